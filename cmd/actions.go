@@ -3,14 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/rezatg/gitc/internal/ai"
 	"github.com/rezatg/gitc/internal/ai/generic"
+	"github.com/rezatg/gitc/internal/feedback"
 	"github.com/rezatg/gitc/internal/git"
+	"github.com/rezatg/gitc/internal/index"
 	"github.com/rezatg/gitc/pkg/config"
 	"github.com/rezatg/gitc/pkg/utils"
+	"github.com/rezatg/gitc/pkg/validator"
 	"github.com/urfave/cli/v2"
 )
 
@@ -19,6 +25,8 @@ import (
 type App struct {
 	gitService git.GitService
 	config     *config.Config
+	resolution *config.Resolution // origins of the settings, if resolved
+	configErr  error              // why the config could not be resolved, for doctor
 }
 
 // NewApp creates a new App instance
@@ -29,35 +37,441 @@ func NewApp(gitService git.GitService, config *config.Config) *App {
 	}
 }
 
-// ConfigureAI builds and validates the AI configuration from CLI context.
-// It merges CLI flags with default values and performs validation.
+// ConfigureAI builds and validates the AI configuration from the resolved
+// configuration, which already includes the flags set on the command line.
 func (a *App) ConfigureAI(c *cli.Context) (*ai.Config, error) {
 	cfg := &ai.Config{
-		Provider:         c.String("provider"),
-		Model:            c.String("model"),
-		APIKey:           c.String("api-key"),
-		Timeout:          time.Duration(c.Int("timeout")) * time.Second,
-		MaxLength:        c.Int("max-length"),
-		Language:         c.String("lang"),
-		MaxRedirects:     c.Int("max-redirects"),
-		Proxy:            c.String("proxy"),
-		CommitType:       c.String("commit-type"),
-		CustomConvention: c.String("custom-convention"),
-		UseGitmoji:       !c.Bool("no-emoji") && c.Bool("emoji"),
-		URL:              c.String("url"),
-	}
-
-	// Apply default values for unset fields
-	a.applyConfigDefaults(cfg)
+		Provider:         a.config.Provider,
+		Model:            a.config.Model,
+		Timeout:          time.Duration(a.config.Timeout) * time.Second,
+		MaxLength:        a.config.MaxLength,
+		Language:         a.config.Language,
+		MaxRedirects:     a.config.MaxRedirects,
+		Proxy:            a.config.Proxy,
+		CommitType:       a.config.CommitType,
+		CustomConvention: a.config.CustomConvention,
+		Preset:           a.config.Preset,
+		UseGitmoji:       a.config.UseGitmoji,
+		URL:              a.config.URL,
+		TicketPattern:    a.config.Ticket.Pattern,
+		TicketPlacement:  a.config.Ticket.Placement,
+		Scope:            c.String("scope"),
+	}
+
+	convention, err := loadConvention(cfg.CustomConvention)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Convention = convention
 
 	// Validate the configuration
 	if err := a.validateConfig(a.config); err != nil {
 		return nil, fmt.Errorf("invalid AI configuration: %w", err)
 	}
+	if cfg.APIKey, err = a.config.ResolveAPIKey(); err != nil {
+		return nil, fmt.Errorf("failed to read API key: %w", err)
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("invalid AI configuration: API key is required (set api_key, api_key_cmd, api_key_file or api_key_env)")
+	}
+	if !utils.ValidTicketPlacement(cfg.TicketPlacement) {
+		return nil, fmt.Errorf("invalid ticket placement %q (expected prefix, scope or footer)", cfg.TicketPlacement)
+	}
+	if !utils.ValidPreset(cfg.Preset) {
+		return nil, fmt.Errorf("unknown preset %q (expected %s)", cfg.Preset, strings.Join(utils.PresetNames(), ", "))
+	}
 
 	return cfg, nil
 }
 
+// loadConvention parses the custom convention and merges it over the rules
+// of a commitlint configuration at the repository root, if there is one. An
+// unreadable commitlint configuration is reported and skipped.
+func loadConvention(custom string) (*config.Convention, error) {
+	conv, err := config.ParseConvention(custom)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return conv, nil // e.g. linting a message file outside a repository
+	}
+	commitlint, _, err := config.LoadCommitlint(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ ignoring commitlint configuration: %v\n", err)
+		return conv, nil
+	}
+
+	return config.MergeConventions(commitlint, conv), nil
+}
+
+// configFlags maps the root flags to the settings they override
+var configFlags = map[string]string{
+	"provider":          "provider",
+	"model":             "model",
+	"url":               "url",
+	"api-key":           "api_key",
+	"proxy":             "proxy",
+	"custom-convention": "custom_convention",
+	"preset":            "preset",
+	"max-length":        "max_length",
+	"lang":              "language",
+	"timeout":           "timeout",
+	"commit-type":       "commit_type",
+	"max-redirects":     "max_redirects",
+	"ticket-pattern":    "ticket.pattern",
+	"ticket-placement":  "ticket.placement",
+}
+
+// resolveConfig merges the global config, the selected profile, the
+// repository config, the environment and the flags set on the command line,
+// reporting an upgrade of the config file and warning about repository
+// settings that may only be set globally
+func resolveConfig(c *cli.Context) (*config.Resolution, error) {
+	opts := config.ResolveOptions{Profile: c.String("profile")}
+	if root, err := git.GetRepoRoot(); err == nil {
+		opts.RepoDir = root
+	}
+
+	for flag, key := range configFlags {
+		if c.IsSet(flag) {
+			opts.Flags = append(opts.Flags, config.Override{Key: key, Value: c.Value(flag), Flag: flag})
+		}
+	}
+	if c.IsSet("no-emoji") {
+		opts.Flags = append(opts.Flags, config.Override{Key: "use_gitmoji", Value: !c.Bool("no-emoji"), Flag: "no-emoji"})
+	} else if c.IsSet("emoji") {
+		opts.Flags = append(opts.Flags, config.Override{Key: "use_gitmoji", Value: c.Bool("emoji"), Flag: "emoji"})
+	}
+
+	resolution, err := config.Resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+	if m := resolution.Migration; m != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ upgraded %s from version %d to %d (the original is kept as %s):\n", m.Path, m.From, m.To, m.Backup)
+		for _, change := range m.Changes {
+			fmt.Fprintf(os.Stderr, "   - %s\n", change)
+		}
+	}
+	for _, key := range resolution.Ignored {
+		fmt.Fprintf(os.Stderr, "⚠️ ignoring %q in %s: it may only be set in the global config\n", key, resolution.RepoFile)
+	}
+	return resolution, nil
+}
+
+// lookupPreset returns the named convention preset; names are validated
+// when the AI configuration is built
+func lookupPreset(name string) utils.Preset {
+	preset, _ := utils.LookupPreset(name)
+	return preset
+}
+
+// promptTemplates loads the prompt templates from ~/.gitc/templates and the
+// repository's .gitc/templates directory, the latter taking precedence
+func promptTemplates() (*utils.PromptTemplates, error) {
+	dirs := []string{filepath.Join(config.Dir(), "templates")}
+	if root, err := git.GetRepoRoot(); err == nil {
+		dirs = append(dirs, filepath.Join(root, ".gitc", "templates"))
+	}
+	return utils.LoadPromptTemplates(dirs...)
+}
+
+// ticketPattern returns the ticket pattern, preferring the custom convention's
+func ticketPattern(cfg *ai.Config) string {
+	if cfg.Convention != nil && cfg.Convention.TicketPattern != "" {
+		return cfg.Convention.TicketPattern
+	}
+	return cfg.TicketPattern
+}
+
+// resolveScope determines the commit scope and whether it is required. An
+// explicitly requested scope is always required; otherwise the scope is
+// inferred from the staged file paths according to the scope config. Presets
+// without Conventional Commits headers have no scope.
+func (a *App) resolveScope(cfg *ai.Config, files []string) (string, bool) {
+	if !lookupPreset(cfg.Preset).Conventional() {
+		return "", false
+	}
+	if cfg.Scope != "" {
+		return cfg.Scope, true
+	}
+
+	mode := a.config.Scope.Mode
+	if mode == utils.ScopeModeOff {
+		return "", false
+	}
+
+	scope, _ := utils.InferScope(files, a.config.Scope.Root, scopeRules(a.config))
+	return scope, scope != "" && mode == utils.ScopeModeRequire
+}
+
+// resolveTypeHint infers the commit type from the staged file paths according
+// to the type hint config. An explicitly requested commit type or a preset
+// without commit types disables it.
+func (a *App) resolveTypeHint(cfg *ai.Config, files []string) (string, bool) {
+	mode := a.config.TypeHint.Mode
+	if cfg.CommitType != "" || mode == utils.TypeHintModeOff || !lookupPreset(cfg.Preset).Conventional() {
+		return "", false
+	}
+
+	typeHint := utils.InferType(files, typeRules(a.config))
+	return typeHint, typeHint != "" && mode == utils.TypeHintModeEnforce
+}
+
+// messageOptions builds the AI message options for the given staged files and
+// diff, loading the prompt templates and resolving ticket IDs, scope, type
+// hints, examples and corrections.
+func (a *App) messageOptions(ctx context.Context, cfg *ai.Config, files []string, diff string) (ai.MessageOptions, error) {
+	templates, err := promptTemplates()
+	if err != nil {
+		return ai.MessageOptions{}, err
+	}
+
+	branch, err := a.gitService.CurrentBranch(ctx)
+	if err != nil {
+		return ai.MessageOptions{}, err
+	}
+	tickets, err := utils.ExtractTickets(branch, ticketPattern(cfg))
+	if err != nil {
+		return ai.MessageOptions{}, fmt.Errorf("failed to resolve ticket IDs: %w", err)
+	}
+
+	scope, scopeRequired := a.resolveScope(cfg, files)
+	typeHint, typeRequired := a.resolveTypeHint(cfg, files)
+	examples := a.historyExamples(ctx, cfg, files, diff)
+	corrections := a.feedbackCorrections(ctx, diff)
+
+	return ai.MessageOptions{
+		Templates:     templates,
+		Preset:        cfg.Preset,
+		Files:         files,
+		Branch:        branch,
+		Model:         cfg.Model,
+		Language:      cfg.Language,
+		CommitType:    cfg.CommitType,
+		Convention:    cfg.Convention,
+		MaxLength:     cfg.MaxLength,
+		MaxRedirects:  cfg.MaxRedirects,
+		Tickets:       tickets,
+		Scope:         scope,
+		ScopeRequired: scopeRequired,
+		TypeHint:      typeHint,
+		TypeRequired:  typeRequired,
+		Types:         a.config.Validation.Types,
+		Examples:      examples,
+		Corrections:   corrections,
+	}, nil
+}
+
+// historyExamples samples commit messages that follow the convention from
+// the repository history to use as prompt examples: the commits most similar
+// to the staged diff or, failing that, the most recent ones, according to the
+// examples config. Failing to read the history is reported and the preset
+// examples are used instead.
+func (a *App) historyExamples(ctx context.Context, cfg *ai.Config, files []string, diff string) []string {
+	examplesCfg := a.config.Examples
+	if examplesCfg.Source == utils.ExampleSourcePreset {
+		return nil
+	}
+
+	filter := git.HistoryFilter{Max: examplesCfg.Depth}
+	if examplesCfg.SamePaths {
+		filter.Paths = files
+	}
+	if examplesCfg.SameAuthor {
+		email, err := git.GetUserEmail(ctx)
+		if err != nil || email == "" {
+			return nil // without an identity there is no author to match
+		}
+		filter.Author = email
+	}
+
+	rules := a.presetRules(lookupPreset(cfg.Preset), cfg.Convention)
+	if examplesCfg.Source == utils.ExampleSourceSimilar {
+		// Extra candidates make up for those failing the rules
+		messages, err := similarMessages(ctx, diff, filter.Author, examplesCfg.Depth, examplesCfg.Count*4)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ using recent examples: %v\n", err)
+		} else if examples := utils.SelectExamples(messages, rules, examplesCfg.Count); examples != nil {
+			return examples
+		}
+	}
+
+	commits, err := git.GetRecentCommitMessages(ctx, filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ using preset examples: %v\n", err)
+		return nil
+	}
+
+	messages := make([]string, len(commits))
+	for i, commit := range commits {
+		messages[i] = commit.Message
+	}
+	return utils.SelectExamples(messages, rules, examplesCfg.Count)
+}
+
+// similarMessages updates the commit index under .git/gitc with the commits
+// added since the last run and returns the messages of up to k indexed
+// commits most similar to the diff, optionally by the given author only.
+func similarMessages(ctx context.Context, diff, author string, depth, k int) ([]string, error) {
+	path, err := index.Path(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ix, err := index.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed, err := ix.Update(ctx, depth); err != nil {
+		return nil, fmt.Errorf("failed to update commit index: %w", err)
+	} else if changed {
+		if err := ix.Save(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var accept func(index.Document) bool
+	if author != "" {
+		accept = func(doc index.Document) bool { return strings.EqualFold(doc.Author, author) }
+	}
+
+	results := ix.Search(index.DiffTerms(diff), k, accept)
+	messages := make([]string, len(results))
+	for i, result := range results {
+		messages[i] = result.Message
+	}
+	return messages, nil
+}
+
+// validationRules builds the rules generated messages are checked against
+func (a *App) validationRules(opts ai.MessageOptions) validator.Rules {
+	preset := lookupPreset(opts.Preset)
+	rules := validator.Rules{
+		Style:             preset.Style,
+		Types:             slices.Clone(preset.ValidationTypes(a.config.Validation.Types)),
+		SubjectCase:       preset.SubjectCase,
+		MaxHeaderLength:   a.config.Validation.MaxHeaderLength,
+		MaxBodyLineLength: a.config.Validation.MaxBodyLineLength,
+		Imperative:        true,
+	}
+
+	// Footers holding ticket IDs are only added after validation
+	for _, token := range preset.Footers {
+		if token != preset.TicketFooter || len(opts.Tickets) == 0 {
+			rules.RequiredFooters = append(rules.RequiredFooters, token)
+		}
+	}
+
+	// An explicitly requested type is always allowed
+	if opts.CommitType != "" && !slices.Contains(rules.Types, opts.CommitType) {
+		rules.Types = append(rules.Types, opts.CommitType)
+	}
+	if opts.ScopeRequired {
+		rules.Scope = opts.Scope
+	}
+
+	applyConventionRules(&rules, opts.Convention)
+
+	// The convention prefix and suffix are only added after validation
+	if rules.MaxHeaderLength > 0 {
+		rules.MaxHeaderLength = max(1, rules.MaxHeaderLength-utils.ConventionAffixLength(opts.Convention, opts.Tickets))
+	}
+	return rules
+}
+
+// applyConventionRules narrows the preset rules with the custom convention
+func applyConventionRules(rules *validator.Rules, conv *config.Convention) {
+	if conv == nil {
+		return
+	}
+
+	if len(conv.Types) > 0 {
+		rules.Types = conv.Types
+	}
+	rules.Scopes = conv.Scopes
+	rules.RequireScope = conv.RequireScope
+	if conv.SubjectCase != "" {
+		rules.SubjectCase = conv.SubjectCase
+	}
+	rules.RequiredFooters = append(rules.RequiredFooters, conv.RequiredFooters...)
+	if conv.MaxHeaderLength > 0 {
+		rules.MaxHeaderLength = conv.MaxHeaderLength
+	}
+	if conv.MaxBodyLineLength > 0 {
+		rules.MaxBodyLineLength = conv.MaxBodyLineLength
+	}
+	rules.HeaderPrefix = utils.AffixPattern(conv.Prefix, conv.TicketPattern)
+	rules.HeaderSuffix = utils.AffixPattern(conv.Suffix, conv.TicketPattern)
+}
+
+// enforceHeader applies the required type and scope to the message header
+func enforceHeader(msg string, opts ai.MessageOptions) string {
+	if opts.TypeRequired {
+		msg = utils.EnforceType(msg, opts.TypeHint)
+	}
+	if opts.ScopeRequired {
+		msg = utils.EnforceScope(msg, opts.Scope)
+	}
+	return msg
+}
+
+// repairMessage validates a generated message and, depending on the
+// validation mode, re-prompts the provider with the violations for a bounded
+// number of attempts before falling back to a local fix-up.
+func (a *App) repairMessage(msg string, opts ai.MessageOptions, generate func(ai.MessageOptions) (string, error)) string {
+	mode := a.config.Validation.Mode
+	if mode == validator.ModeOff {
+		return msg
+	}
+
+	rules := a.validationRules(opts)
+	msg = enforceHeader(msg, opts)
+
+	for attempt := 0; mode == validator.ModeRepair && attempt < a.config.Validation.MaxAttempts; attempt++ {
+		violations := validator.Validate(msg, rules)
+		if len(violations) == 0 {
+			return msg
+		}
+
+		retry := opts
+		retry.Previous = msg
+		for _, v := range violations {
+			retry.Violations = append(retry.Violations, v.Message)
+		}
+
+		repaired, err := generate(retry)
+		if err != nil {
+			break // keep the last message and fix it locally
+		}
+		msg = enforceHeader(repaired, opts)
+	}
+
+	if len(validator.Validate(msg, rules)) > 0 {
+		msg = validator.Fix(msg, rules)
+	}
+	return msg
+}
+
+// finalizeMessage applies the deterministic post-processing steps to a
+// generated message: required type and scope, Gitmoji and ticket IDs.
+func (a *App) finalizeMessage(msg string, cfg *ai.Config, opts ai.MessageOptions) string {
+	// Make sure the required type and scope are present in the header
+	msg = enforceHeader(msg, opts)
+
+	// Apply Gitmoji if enabled; it is derived from the Conventional Commits type
+	preset := lookupPreset(cfg.Preset)
+	if cfg.UseGitmoji && preset.Conventional() {
+		msg = utils.AddGitmojiToCommitMessage(msg)
+	}
+
+	// Add the convention prefix and suffix, then any tickets not placed yet
+	msg = utils.ApplyConventionAffixes(msg, cfg.Convention, opts.Tickets)
+	return utils.ApplyTickets(msg, opts.Tickets, preset.TicketPlacement(cfg.TicketPlacement), preset.TicketFooter)
+}
+
 // generateCommitMessage creates a commit message using AI based on the provided git diff.
 // It handles AI provider initialization, timeout management, and Gitmoji formatting.
 func (a *App) generateCommitMessage(ctx context.Context, diff string, cfg *ai.Config) (string, error) {
@@ -66,34 +480,42 @@ func (a *App) generateCommitMessage(ctx context.Context, diff string, cfg *ai.Co
 		return "", fmt.Errorf("failed to initialize AI provider: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
-	defer cancel()
-
-	opts := ai.MessageOptions{
-		Model:            cfg.Model,
-		Language:         cfg.Language,
-		CommitType:       cfg.CommitType,
-		CustomConvention: cfg.CustomConvention,
-		MaxLength:        cfg.MaxLength,
-		MaxRedirects:     cfg.MaxRedirects,
+	files, err := a.gitService.GetStagedFiles(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list staged files: %w", err)
 	}
 
-	msg, err := provider.GenerateCommitMessage(ctx, diff, opts)
+	opts, err := a.messageOptions(ctx, cfg, files, diff)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate commit message: %w", err)
+		return "", err
 	}
 
-	// Apply Gitmoji if enabled
-	if cfg.UseGitmoji {
-		msg = utils.AddGitmojiToCommitMessage(msg)
+	// Each request, including repair attempts, gets its own timeout
+	generate := func(opts ai.MessageOptions) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+		return provider.GenerateCommitMessage(ctx, diff, opts)
+	}
+
+	msg, err := generate(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
 	}
 
+	msg = a.repairMessage(msg, opts, generate)
+	msg = a.finalizeMessage(msg, cfg, opts)
+	a.recordFeedback(ctx, diff, msg)
 	return msg, nil
 }
 
 // formatGitCommand formats the git commit command for display based on message content.
-// Handles both single-line and multi-line commit messages.
-func formatGitCommand(msg string) string {
+// Handles both single-line and multi-line commit messages, signed off if requested.
+func formatGitCommand(msg string, signOff bool) string {
+	command := "git commit"
+	if signOff {
+		command += " -s"
+	}
+
 	lines := strings.Split(msg, "\n")
 	nonEmptyLines := make([]string, 0, len(lines))
 
@@ -105,15 +527,15 @@ func formatGitCommand(msg string) string {
 	}
 
 	if len(nonEmptyLines) == 0 {
-		return "git commit -m \"\""
+		return command + " -m \"\""
 	}
 
 	if len(nonEmptyLines) == 1 {
-		return fmt.Sprintf("git commit -m \"%s\"", nonEmptyLines[0])
+		return fmt.Sprintf("%s -m \"%s\"", command, nonEmptyLines[0])
 	}
 
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("git commit -m \"%s\"", nonEmptyLines[0]))
+	builder.WriteString(fmt.Sprintf("%s -m \"%s\"", command, nonEmptyLines[0]))
 
 	for _, line := range nonEmptyLines[1:] {
 		builder.WriteString(fmt.Sprintf(" \\\n    -m \"%s\"", line))
@@ -155,73 +577,28 @@ func (a *App) CommitAction(c *cli.Context) error {
 
 	// Display the generated command
 	fmt.Println("✅ Commit message generated. You can now run:")
-	fmt.Printf("   %s\n", formatGitCommand(msg))
+	fmt.Printf("   %s\n", formatGitCommand(msg, lookupPreset(cfg.Preset).SignOff))
 
 	return nil
 }
 
 // ConfigAction handles updating and saving application configuration.
+// Profile settings are saved to the profile selected with --profile.
 func (a *App) ConfigAction(c *cli.Context) error {
-	cfg := *a.config
-	a.updateConfigFromFlags(&cfg, c)
-
-	if err := a.validateConfig(&cfg); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
-	}
-
-	if err := config.Save(&cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
-
-	a.config = &cfg
-	fmt.Println("✅ Configuration updated successfully")
-	return nil
-}
-
-// applyConfigDefaults sets sensible default values for unset AI configuration fields.
-func (a *App) applyConfigDefaults(cfg *ai.Config) {
-	if cfg.Provider == "" {
-		cfg.Provider = a.config.Provider
-	}
-	if cfg.Model == "" {
-		switch cfg.Provider {
-		case "openai":
-			cfg.Model = "gpt-4o-mini"
-		case "grok":
-			cfg.Model = "grok-3"
-		case "deepseek":
-			cfg.Model = "deepseek-rag"
-		default:
-			cfg.Model = a.config.Model
-		}
-	}
-	if cfg.APIKey == "" {
-		cfg.APIKey = a.config.APIKey
-	}
-	if cfg.Timeout == 0 {
-		cfg.Timeout = time.Duration(a.config.Timeout) * time.Second
-	}
-	if cfg.MaxLength == 0 {
-		cfg.MaxLength = a.config.MaxLength
-	}
-	if cfg.Language == "" {
-		cfg.Language = a.config.Language
-	}
-	if cfg.MaxRedirects == 0 {
-		cfg.MaxRedirects = a.config.MaxRedirects
-	}
-	if cfg.URL == "" {
-		switch cfg.Provider {
-		case "openai":
-			cfg.URL = "https://api.openai.com/v1/chat/completions"
-		case "grok":
-			cfg.URL = "https://api.x.ai/v1/chat/completions"
-		case "deepseek":
-			cfg.URL = "https://api.deepseek.com/v1/chat/completions"
-		default:
-			cfg.URL = a.config.URL
+	return a.updateConfig(func(cfg *config.Config) error {
+		if name := c.String("profile"); name != "" && name != config.DefaultProfileName {
+			profile := cfg.Profiles[name]
+			updateProfileFromFlags(&profile, c)
+			if cfg.Profiles == nil {
+				cfg.Profiles = make(map[string]config.Profile)
+			}
+			cfg.Profiles[name] = profile
+		} else {
+			updateProfileFromFlags(&cfg.Profile, c)
 		}
-	}
+		a.updateConfigFromFlags(cfg, c)
+		return nil
+	})
 }
 
 // initAIProvider initializes the appropriate AI provider based on configuration.
@@ -235,48 +612,111 @@ func (a *App) validateConfig(cfg *config.Config) error {
 	if cfg.Provider == "" {
 		return fmt.Errorf("AI provider is required")
 	}
-	if cfg.APIKey == "" {
-		return fmt.Errorf("API key is required")
-	}
 	if cfg.Timeout <= 0 {
 		return fmt.Errorf("timeout must be positive")
 	}
 	if cfg.MaxLength <= 0 {
 		return fmt.Errorf("max length must be positive")
 	}
+	if cfg.Ticket.Placement != "" && !utils.ValidTicketPlacement(cfg.Ticket.Placement) {
+		return fmt.Errorf("invalid ticket placement %q (expected prefix, scope or footer)", cfg.Ticket.Placement)
+	}
+	if cfg.Scope.Mode != "" && !utils.ValidScopeMode(cfg.Scope.Mode) {
+		return fmt.Errorf("invalid scope mode %q (expected off, suggest or require)", cfg.Scope.Mode)
+	}
+	if cfg.TypeHint.Mode != "" && !utils.ValidTypeHintMode(cfg.TypeHint.Mode) {
+		return fmt.Errorf("invalid type hint mode %q (expected off, hint or enforce)", cfg.TypeHint.Mode)
+	}
+	if _, err := config.ParseConvention(cfg.CustomConvention); err != nil {
+		return err
+	}
+	if !utils.ValidPreset(cfg.Preset) {
+		return fmt.Errorf("unknown preset %q (expected %s)", cfg.Preset, strings.Join(utils.PresetNames(), ", "))
+	}
+	if _, err := cfg.KeySource(); err != nil {
+		return err
+	}
+	for name, profile := range cfg.Profiles {
+		if _, err := profile.KeySource(); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+		if profile.Preset != "" && !utils.ValidPreset(profile.Preset) {
+			return fmt.Errorf("unknown preset %q in profile %q", profile.Preset, name)
+		}
+		if _, err := config.ParseConvention(profile.CustomConvention); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+	if cfg.DefaultProfile != "" && !cfg.HasProfile(cfg.DefaultProfile) {
+		return fmt.Errorf("unknown default profile %q (expected %s)", cfg.DefaultProfile, strings.Join(cfg.ProfileNames(), ", "))
+	}
+	if cfg.Validation.Mode != "" && !validator.ValidMode(cfg.Validation.Mode) {
+		return fmt.Errorf("invalid validation mode %q (expected repair, fix or off)", cfg.Validation.Mode)
+	}
+	if cfg.Examples.Source != "" && !utils.ValidExampleSource(cfg.Examples.Source) {
+		return fmt.Errorf("invalid examples source %q (expected history, similar or preset)", cfg.Examples.Source)
+	}
+	if cfg.Feedback.Mode != "" && !feedback.ValidMode(cfg.Feedback.Mode) {
+		return fmt.Errorf("invalid feedback mode %q (expected learn or off)", cfg.Feedback.Mode)
+	}
 	return nil
 }
 
-// updateConfigFromFlags updates the configuration with values from CLI flags.
-// Only updates fields that are explicitly set in the context.
-func (a *App) updateConfigFromFlags(cfg *config.Config, c *cli.Context) {
+// updateProfileFromFlags updates the profile settings with values from CLI
+// flags. Only updates fields that are explicitly set in the context.
+func updateProfileFromFlags(profile *config.Profile, c *cli.Context) {
 	if provider := c.String("provider"); provider != "" {
-		cfg.Provider = provider
+		profile.Provider = provider
 	}
 	if model := c.String("model"); model != "" {
-		cfg.Model = model
+		profile.Model = model
+	}
+	// The key is read from a single source
+	for _, source := range []struct {
+		flag  string
+		value *string
+	}{
+		{"api-key", &profile.APIKey},
+		{"api-key-cmd", &profile.APIKeyCmd},
+		{"api-key-file", &profile.APIKeyFile},
+		{"api-key-env", &profile.APIKeyEnv},
+	} {
+		if value := c.String(source.flag); value != "" {
+			profile.APIKey, profile.APIKeyCmd, profile.APIKeyFile, profile.APIKeyEnv = "", "", "", ""
+			*source.value = value
+			break
+		}
+	}
+	if url := c.String("url"); url != "" {
+		profile.URL = url
+	}
+	if proxy := c.String("proxy"); proxy != "" {
+		profile.Proxy = proxy
+	}
+	if customConvention := c.String("custom-convention"); customConvention != "" {
+		profile.CustomConvention = customConvention
 	}
-	if apiKey := c.String("api-key"); apiKey != "" {
-		cfg.APIKey = apiKey
+	if preset := c.String("preset"); preset != "" {
+		profile.Preset = preset
 	}
+}
+
+// updateConfigFromFlags updates the configuration with values from CLI flags.
+// Only updates fields that are explicitly set in the context; profile
+// settings are handled by updateProfileFromFlags.
+func (a *App) updateConfigFromFlags(cfg *config.Config, c *cli.Context) {
 	if lang := c.String("lang"); lang != "" {
 		cfg.Language = lang
 	}
 	if timeout := c.Int("timeout"); timeout != 0 {
 		cfg.Timeout = timeout
 	}
-	if maxLength := c.Int("maxLength"); maxLength != 0 {
+	if maxLength := c.Int("max-length"); maxLength != 0 {
 		cfg.MaxLength = maxLength
 	}
-	if proxy := c.String("proxy"); proxy != "" {
-		cfg.Proxy = proxy
-	}
 	if commitType := c.String("commit-type"); commitType != "" {
 		cfg.CommitType = commitType
 	}
-	if customConvention := c.String("custom-convention"); customConvention != "" {
-		cfg.CustomConvention = customConvention
-	}
 	if c.IsSet("no-emoji") {
 		cfg.UseGitmoji = !c.Bool("no-emoji")
 	} else if c.IsSet("emoji") {
@@ -285,7 +725,31 @@ func (a *App) updateConfigFromFlags(cfg *config.Config, c *cli.Context) {
 	if maxRedirects := c.Int("max-redirects"); maxRedirects != 0 {
 		cfg.MaxRedirects = maxRedirects
 	}
-	if url := c.String("url"); url != "" {
-		cfg.URL = url
+	if pattern := c.String("ticket-pattern"); pattern != "" {
+		cfg.Ticket.Pattern = pattern
+	}
+	if placement := c.String("ticket-placement"); placement != "" {
+		cfg.Ticket.Placement = placement
+	}
+	if scopeMode := c.String("scope-mode"); scopeMode != "" {
+		cfg.Scope.Mode = scopeMode
+	}
+	if c.IsSet("scope-root") {
+		cfg.Scope.Root = c.String("scope-root")
+	}
+	if typeHintMode := c.String("type-hint-mode"); typeHintMode != "" {
+		cfg.TypeHint.Mode = typeHintMode
+	}
+	if validationMode := c.String("validation-mode"); validationMode != "" {
+		cfg.Validation.Mode = validationMode
+	}
+	if examplesSource := c.String("examples-source"); examplesSource != "" {
+		cfg.Examples.Source = examplesSource
+	}
+	if feedbackMode := c.String("feedback-mode"); feedbackMode != "" {
+		cfg.Feedback.Mode = feedbackMode
+	}
+	if c.IsSet("default-profile") {
+		cfg.DefaultProfile = c.String("default-profile")
 	}
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/rezatg/gitc/internal/git"
 	"github.com/rezatg/gitc/pkg/config"
+	"github.com/rezatg/gitc/pkg/utils"
 	"github.com/urfave/cli/v2"
 )
 
@@ -27,69 +28,86 @@ var Commands = &cli.App{
 		},
 		&cli.StringFlag{
 			Name:  "provider",
-			Value: "openai",
 			Usage: "AI provider to use (openai, anthropic)",
 		},
 		&cli.StringFlag{
 			Name:  "model",
-			Value: "gpt-4o-mini",
 			Usage: "Specify the OpenAI model",
 		},
+		&cli.StringFlag{
+			Name:    "url",
+			Aliases: []string{"u"},
+			Usage:   "Custom API URL for the AI provider",
+		},
 		&cli.StringFlag{
 			Name:  "lang",
-			Value: "en",
 			Usage: "Set commit message language (en, fa, ru, etc.)",
 		},
 		&cli.IntFlag{
 			Name:  "timeout",
-			Value: 10,
 			Usage: "Set request timeout in seconds",
 		},
 		&cli.IntFlag{
-			Name:  "maxLength",
-			Value: 200,
-			Usage: "Set maximum output length of AI response",
+			Name:    "max-length",
+			Aliases: []string{"maxLength"},
+			Usage:   "Set maximum output length of AI response",
 		},
 		&cli.StringFlag{
 			Name:    "api-key",
 			Aliases: []string{"k"},
 			Usage:   "API key for the AI provider",
-			EnvVars: []string{"AI_API_KEY"},
 		},
 		&cli.StringFlag{
 			Name:    "proxy",
 			Aliases: []string{"p"},
 			Usage:   "Proxy URL for API requests (e.g., http://proxy.example.com:8080)",
-			EnvVars: []string{"GITC_PROXY"},
 		},
 		&cli.StringFlag{
 			Name:    "commit-type",
 			Aliases: []string{"t"},
 			Usage:   "Commit type for Conventional Commits (e.g., feat, fix, docs)",
-			EnvVars: []string{"GITC_COMMIT_TYPE"},
 		},
 		&cli.StringFlag{
 			Name:    "custom-convention",
 			Aliases: []string{"C"},
 			Usage:   "Custom commit message convention in JSON format (e.g., '{\"prefix\": \"JIRA-123\"}')",
-			EnvVars: []string{"GITC_CUSTOM_CONVENTION"},
+		},
+		&cli.StringFlag{
+			Name:  "preset",
+			Usage: "Commit message convention preset (conventional, angular, gitmoji, kernel, chromium, plain)",
 		},
 		&cli.BoolFlag{
 			Name:    "emoji",
 			Aliases: []string{"g"},
 			Usage:   "Add Gitmoji to the commit message based on commit type",
-			EnvVars: []string{"GITC_GITMOJI"},
 		},
 		&cli.BoolFlag{
 			Name:  "no-emoji",
 			Usage: "Disable Gitmoji in the commit message (overrides --emoji)",
 		},
+		&cli.StringFlag{
+			Name:  "ticket-pattern",
+			Usage: "Regular expression used to extract ticket IDs from the branch name",
+		},
+		&cli.StringFlag{
+			Name:  "ticket-placement",
+			Usage: "Where to place ticket IDs in the commit message (prefix, scope, footer)",
+		},
+		&cli.StringFlag{
+			Name:    "scope",
+			Aliases: []string{"s"},
+			Usage:   "Scope to use in the commit message instead of inferring it from changed paths",
+			EnvVars: []string{"GITC_SCOPE"},
+		},
 		&cli.IntFlag{
 			Name:    "max-redirects",
 			Aliases: []string{"r"},
-			Value:   5,
 			Usage:   "Maximum number of HTTP redirects to follow",
-			EnvVars: []string{"GITC_MAX_REDIRECTS"},
+		},
+		&cli.StringFlag{
+			Name:    "profile",
+			Usage:   "Configuration profile to use (e.g., work, personal, local)",
+			EnvVars: []string{"GITC_PROFILE"},
 		},
 		&cli.StringFlag{
 			Name:    "config",
@@ -104,15 +122,24 @@ var Commands = &cli.App{
 			config.SetConfigPath(configPath)
 		}
 
-		// Load config
-		cfg, err := config.Load()
+		// Resolve the effective config from the config files, the
+		// environment and the flags
+		resolution, err := resolveConfig(c)
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			// doctor reports a broken configuration rather than failing
+			if c.Args().First() != "doctor" {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			appInstance = NewApp(git.NewGitService(), config.DefaultConfig())
+			appInstance.configErr = err
+			return nil
 		}
+		cfg := resolution.Config
 
 		// Initialize dependencies
-		gitService := git.NewGitService()
+		gitService := git.NewGitServiceWithLimits(diffLimits(cfg), cfg.ExcludeFiles...)
 		appInstance = NewApp(gitService, cfg)
+		appInstance.resolution = resolution
 		return nil
 	},
 	Action: func(c *cli.Context) error {
@@ -133,6 +160,11 @@ var Commands = &cli.App{
 					Name:  "model",
 					Usage: "Specify the OpenAI model",
 				},
+				&cli.StringFlag{
+					Name:    "url",
+					Aliases: []string{"u"},
+					Usage:   "Custom API URL for the AI provider",
+				},
 				&cli.StringFlag{
 					Name:  "lang",
 					Usage: "Set commit message language (en, fa, ru, etc.)",
@@ -147,8 +179,9 @@ var Commands = &cli.App{
 					Usage: "Set request timeout in seconds",
 				},
 				&cli.IntFlag{
-					Name:  "maxLength",
-					Usage: "Set maximum output length of AI response",
+					Name:    "max-length",
+					Aliases: []string{"maxLength"},
+					Usage:   "Set maximum output length of AI response",
 				},
 				&cli.IntFlag{
 					Name:    "max-redirects",
@@ -158,7 +191,19 @@ var Commands = &cli.App{
 				&cli.StringFlag{
 					Name:    "api-key",
 					Aliases: []string{"k"},
-					Usage:   "API key for the AI provider",
+					Usage:   "API key for the AI provider (saved in plain text)",
+				},
+				&cli.StringFlag{
+					Name:  "api-key-cmd",
+					Usage: "Command printing the API key (e.g., 'pass show openai')",
+				},
+				&cli.StringFlag{
+					Name:  "api-key-file",
+					Usage: "File holding the API key",
+				},
+				&cli.StringFlag{
+					Name:  "api-key-env",
+					Usage: "Environment variable holding the API key",
 				},
 				&cli.StringFlag{
 					Name:    "commit-type",
@@ -170,6 +215,10 @@ var Commands = &cli.App{
 					Aliases: []string{"C"},
 					Usage:   "Custom commit message convention in JSON format (e.g., '{\"prefix\": \"JIRA-123\"}')",
 				},
+				&cli.StringFlag{
+					Name:  "preset",
+					Usage: "Commit message convention preset (conventional, angular, gitmoji, kernel, chromium, plain)",
+				},
 				&cli.BoolFlag{
 					Name:    "emoji",
 					Aliases: []string{"g"},
@@ -179,6 +228,46 @@ var Commands = &cli.App{
 					Name:  "no-emoji",
 					Usage: "Disable Gitmoji in the commit message",
 				},
+				&cli.StringFlag{
+					Name:  "ticket-pattern",
+					Usage: "Regular expression used to extract ticket IDs from the branch name",
+				},
+				&cli.StringFlag{
+					Name:  "ticket-placement",
+					Usage: "Where to place ticket IDs in the commit message (prefix, scope, footer)",
+				},
+				&cli.StringFlag{
+					Name:  "scope-mode",
+					Usage: "How to use the scope inferred from changed paths (off, suggest, require)",
+				},
+				&cli.StringFlag{
+					Name:  "scope-root",
+					Usage: "Directory whose subdirectories are used as scopes (e.g., packages)",
+				},
+				&cli.StringFlag{
+					Name:  "type-hint-mode",
+					Usage: "How to use the commit type inferred from changed paths (off, hint, enforce)",
+				},
+				&cli.StringFlag{
+					Name:  "validation-mode",
+					Usage: "What to do with generated messages that break the commit rules (repair, fix, off)",
+				},
+				&cli.StringFlag{
+					Name:  "examples-source",
+					Usage: "Where the example messages in the prompt come from (history, similar, preset)",
+				},
+				&cli.StringFlag{
+					Name:  "feedback-mode",
+					Usage: "Whether to learn from edits made to generated messages before committing (learn, off)",
+				},
+				&cli.StringFlag{
+					Name:  "profile",
+					Usage: "Save the provider, model, key, URL, proxy and convention settings to this profile",
+				},
+				&cli.StringFlag{
+					Name:  "default-profile",
+					Usage: "Profile used when none is selected with --profile",
+				},
 				&cli.StringFlag{
 					Name:    "config",
 					Aliases: []string{"c"},
@@ -198,11 +287,186 @@ var Commands = &cli.App{
 					return fmt.Errorf("failed to load config: %w", err)
 				}
 
-				// Initialize dependencies
-				gitService := git.NewGitService()
+				// Initialize dependencies; the repository config is not merged
+				// so that its settings are not saved to the global config
+				gitService := git.NewGitServiceWithLimits(diffLimits(cfg), cfg.ExcludeFiles...)
 				app := NewApp(gitService, cfg)
 				return app.ConfigAction(c)
 			},
+			Subcommands: []*cli.Command{
+				{
+					Name:  "show",
+					Usage: "Print the effective configuration",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "origin",
+							Usage: "Print where each setting comes from",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return appInstance.ConfigShowAction(c)
+					},
+				}, {
+					Name:      "get",
+					Usage:     "Print the effective value of a setting (e.g., ticket.pattern)",
+					ArgsUsage: "<key>",
+					Action: func(c *cli.Context) error {
+						return appInstance.ConfigGetAction(c)
+					},
+				}, {
+					Name:      "set",
+					Usage:     "Set a setting in the global config file",
+					ArgsUsage: "<key> <value>",
+					Action: func(c *cli.Context) error {
+						return appInstance.ConfigSetAction(c)
+					},
+				}, {
+					Name:      "unset",
+					Usage:     "Clear a setting in the global config file, restoring its default",
+					ArgsUsage: "<key>",
+					Action: func(c *cli.Context) error {
+						return appInstance.ConfigUnsetAction(c)
+					},
+				}, {
+					Name:  "list",
+					Usage: "List the settings of the global config file",
+					Action: func(c *cli.Context) error {
+						return appInstance.ConfigListAction(c)
+					},
+				}, {
+					Name:  "edit",
+					Usage: "Open the global config file in $EDITOR, saving it once valid",
+					Action: func(c *cli.Context) error {
+						return appInstance.ConfigEditAction(c)
+					},
+				}, {
+					Name:  "convert",
+					Usage: "Rewrite the global config file in another format, keeping a backup",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "to",
+							Usage:    "Target format (json, yaml, toml)",
+							Required: true,
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return appInstance.ConfigConvertAction(c)
+					},
+				},
+			},
+		}, {
+			Name:  "split",
+			Usage: "Split staged changes into multiple logical commits",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Create the proposed commits without asking for confirmation",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return appInstance.SplitAction(c)
+			},
+		}, {
+			Name:      "lint",
+			Usage:     "Lint commit messages against the rules of the convention preset",
+			ArgsUsage: "[file|-]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "range",
+					Usage: "Lint the commits in a revision range (e.g., origin/main..HEAD)",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Output format (text, json)",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return appInstance.LintAction(c)
+			},
+		}, {
+			Name:  "hook",
+			Usage: "Manage the commit-msg hook that lints every commit",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "install",
+					Usage: "Install the commit-msg hook",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "force",
+							Usage: "Replace an existing commit-msg hook, keeping a backup",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						return appInstance.HookInstallAction(c)
+					},
+				}, {
+					Name:  "uninstall",
+					Usage: "Remove the commit-msg hook installed by gitc",
+					Action: func(c *cli.Context) error {
+						return appInstance.HookUninstallAction(c)
+					},
+				},
+			},
+		}, {
+			Name:  "prompt",
+			Usage: "Inspect the prompts sent to the AI provider",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "show",
+					Usage: "Print the rendered prompts for the staged changes",
+					Action: func(c *cli.Context) error {
+						return appInstance.PromptShowAction(c)
+					},
+				},
+			},
+		}, {
+			Name:  "feedback",
+			Usage: "Manage the corrections learned from edited commit messages",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "list",
+					Usage: "List the recorded corrections",
+					Action: func(c *cli.Context) error {
+						return appInstance.FeedbackListAction(c)
+					},
+				}, {
+					Name:  "clear",
+					Usage: "Delete the recorded corrections of the repository",
+					Action: func(c *cli.Context) error {
+						return appInstance.FeedbackClearAction(c)
+					},
+				},
+			},
+		}, {
+			Name:  "init",
+			Usage: "Set up the provider, API key, model and convention interactively",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "global",
+					Usage: "Save the settings to the global config",
+				},
+				&cli.BoolFlag{
+					Name:  "repo",
+					Usage: "Save the language, convention and Gitmoji settings to the repository config",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return appInstance.InitAction(c)
+			},
+		}, {
+			Name:  "models",
+			Usage: "List the models offered by the configured provider",
+			Action: func(c *cli.Context) error {
+				return appInstance.ModelsAction(c)
+			},
+		}, {
+			Name:  "doctor",
+			Usage: "Check git, the configuration and the connection to the AI provider",
+			Action: func(c *cli.Context) error {
+				return appInstance.DoctorAction(c)
+			},
 		}, {
 			Name:  "reset-config",
 			Usage: "Reset gitc configuration to default values",
@@ -217,3 +481,31 @@ var Commands = &cli.App{
 		},
 	},
 }
+
+// diffLimits converts the diff section of the config into git diff limits
+func diffLimits(cfg *config.Config) git.DiffLimits {
+	return git.DiffLimits{
+		MaxBytes:     cfg.Diff.MaxBytes,
+		MaxLines:     cfg.Diff.MaxLines,
+		MaxFileBytes: cfg.Diff.MaxFileBytes,
+		MaxFileLines: cfg.Diff.MaxFileLines,
+	}
+}
+
+// scopeRules converts the configured scope rules into utils scope rules
+func scopeRules(cfg *config.Config) []utils.ScopeRule {
+	rules := make([]utils.ScopeRule, len(cfg.Scope.Rules))
+	for i, rule := range cfg.Scope.Rules {
+		rules[i] = utils.ScopeRule{Pattern: rule.Pattern, Scope: rule.Scope}
+	}
+	return rules
+}
+
+// typeRules converts the configured type hint rules into utils type rules
+func typeRules(cfg *config.Config) []utils.TypeRule {
+	rules := make([]utils.TypeRule, len(cfg.TypeHint.Rules))
+	for i, rule := range cfg.TypeHint.Rules {
+		rules[i] = utils.TypeRule{Pattern: rule.Pattern, Type: rule.Type}
+	}
+	return rules
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bytedance/sonic"
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+// ConfigShowAction prints the effective configuration and, with --origin,
+// where each setting comes from
+func (a *App) ConfigShowAction(c *cli.Context) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range a.resolution.Settings() {
+		value := formatSetting(s.Key, s.Value)
+		if c.Bool("origin") {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, value, s.Origin)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", s.Key, value)
+		}
+	}
+	return w.Flush()
+}
+
+// ConfigGetAction prints the effective value of a setting; API keys are
+// masked
+func (a *App) ConfigGetAction(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return cli.Exit("❌ usage: gitc config get <key>", 2)
+	}
+
+	key := c.Args().First()
+	value, err := a.config.Get(key)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	if s, ok := value.(string); ok && !isSecret(key) {
+		fmt.Println(s)
+		return nil
+	}
+	fmt.Println(formatSetting(key, value))
+	return nil
+}
+
+// ConfigSetAction sets a setting in the global config file
+func (a *App) ConfigSetAction(c *cli.Context) error {
+	if c.Args().Len() != 2 {
+		return cli.Exit("❌ usage: gitc config set <key> <value>", 2)
+	}
+
+	return a.updateConfig(func(cfg *config.Config) error {
+		return cfg.Set(c.Args().Get(0), c.Args().Get(1))
+	})
+}
+
+// ConfigUnsetAction clears a setting in the global config file, so that its
+// default applies
+func (a *App) ConfigUnsetAction(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return cli.Exit("❌ usage: gitc config unset <key>", 2)
+	}
+
+	return a.updateConfig(func(cfg *config.Config) error {
+		return cfg.Unset(c.Args().First())
+	})
+}
+
+// ConfigListAction prints the settings of the global config file as
+// key=value lines
+func (a *App) ConfigListAction(c *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("❌ failed to load config: %w", err)
+	}
+	settings, err := cfg.List()
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	for _, s := range settings {
+		fmt.Printf("%s=%s\n", s.Key, formatSetting(s.Key, s.Value))
+	}
+	return nil
+}
+
+// ConfigEditAction opens the global config file in the editor and saves it
+// once it is valid. The file is edited as a copy, so that an invalid
+// configuration is never saved.
+func (a *App) ConfigEditAction(c *cli.Context) error {
+	path, err := config.Path()
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := config.Save(config.DefaultConfig()); err != nil {
+			return fmt.Errorf("❌ failed to create config: %w", err)
+		}
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("❌ failed to read config: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "config-*"+filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("❌ failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("❌ failed to write temporary file: %w", err)
+	}
+
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return fmt.Errorf("❌ failed to read edited config: %w", err)
+		}
+
+		cfg, err := config.Parse(edited, config.FormatOf(path))
+		if err == nil {
+			err = a.validateConfig(cfg)
+		}
+		if err == nil {
+			if bytes.Equal(edited, original) {
+				fmt.Println("Configuration unchanged")
+				return nil
+			}
+			if err := os.Rename(tmp.Name(), path); err != nil {
+				return fmt.Errorf("❌ failed to save config: %w", err)
+			}
+			fmt.Println("✅ Configuration updated successfully")
+			if before, err := config.Parse(original, config.FormatOf(path)); err != nil || modelChanged(before, cfg) {
+				a.warnUnknownModel(c.Context, cfg)
+			}
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "❌ invalid configuration: %v\n", err)
+		if !confirm("Edit again?") {
+			return fmt.Errorf("❌ configuration not saved")
+		}
+	}
+}
+
+// ConfigConvertAction rewrites the global config file in another format,
+// keeping the previous file as a backup
+func (a *App) ConfigConvertAction(c *cli.Context) error {
+	format := c.String("to")
+	if !config.ValidFormat(format) {
+		return cli.Exit(fmt.Sprintf("❌ unknown format %q (expected json, yaml or toml)", format), 2)
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("❌ failed to load config: %w", err)
+	}
+	from := config.FormatOf(path)
+	if from == config.Format(format) {
+		fmt.Printf("%s is already in %s\n", path, format)
+		return nil
+	}
+
+	target := strings.TrimSuffix(path, filepath.Ext(path)) + "." + format
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("❌ %s already exists", target)
+	}
+	data, err := config.Encode(cfg, config.Format(format))
+	if err != nil {
+		return fmt.Errorf("❌ failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		return fmt.Errorf("❌ failed to write config: %w", err)
+	}
+	if err := os.Rename(path, path+".bak"); err != nil {
+		return fmt.Errorf("❌ failed to back up %s: %w", path, err)
+	}
+
+	if from != config.FormatJSON {
+		fmt.Fprintf(os.Stderr, "⚠️ comments in %s were not converted\n", filepath.Base(path))
+	}
+	fmt.Printf("✅ Converted %s to %s (the previous file is kept as %s.bak)\n", path, target, filepath.Base(path))
+	return nil
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, defaulting to vi, and waits
+// for the editor to exit
+func runEditor(path string) error {
+	editor := cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi")
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+// updateConfig applies fn to the settings of the global config file and
+// saves the ones it changed, once the config they result in, with the
+// defaults of the settings left unset, is valid. A change of model is
+// checked against the models offered by the provider.
+func (a *App) updateConfig(fn func(cfg *config.Config) error) error {
+	var effective config.Config
+	previous, err := config.Load()
+	updateErr := config.Update(func(cfg *config.Config) error {
+		if err := fn(cfg); err != nil {
+			return err
+		}
+		effective = *cfg
+		config.ApplyDefaults(&effective)
+		if err := a.validateConfig(&effective); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		return nil
+	})
+	if updateErr != nil {
+		return fmt.Errorf("❌ %w", updateErr)
+	}
+	fmt.Println("✅ Configuration updated successfully")
+	if err != nil || modelChanged(previous, &effective) {
+		a.warnUnknownModel(context.Background(), &effective)
+	}
+	return nil
+}
+
+// modelChanged reports whether the provider, model or URL of two configs
+// differ
+func modelChanged(before, after *config.Config) bool {
+	return before.Provider != after.Provider || before.Model != after.Model || before.URL != after.URL
+}
+
+// formatSetting formats a setting value for display, masking API keys
+func formatSetting(key string, value any) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return `""`
+		} else if isSecret(key) {
+			return maskSecret(v)
+		}
+		return v
+	case nil:
+		return "-"
+	}
+
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// isSecret reports whether a setting holds an API key
+func isSecret(key string) bool {
+	return key == "api_key" || strings.HasSuffix(key, ".api_key")
+}
+
+// maskSecret hides all but the last four characters of a secret
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", 8) + secret[len(secret)-4:]
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/rezatg/gitc/pkg/config"
+)
+
+// ------------------- config get -------------------
+
+func TestConfigGetAction(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "sk-0123456789abcdef"
+	cfg.Profiles = map[string]config.Profile{"work": {APIKey: "sk-work-0123456789"}}
+	app := NewApp(nil, cfg)
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"api_key", "********cdef\n"},
+		{"profiles.work.api_key", "********6789\n"},
+		{"model", "gpt-4o-mini\n"},
+		{"proxy", "\n"},
+		{"validation.max_attempts", "2\n"},
+	}
+
+	for _, tt := range tests {
+		out, err := captureStdout(t, func() error { return app.ConfigGetAction(newTestContext(t, nil, nil, tt.key)) })
+		if err != nil || out != tt.want {
+			t.Errorf("config get %s printed %q, want %q (%v)", tt.key, out, tt.want, err)
+		}
+	}
+}
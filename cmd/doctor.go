@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rezatg/gitc/internal/ai"
+	"github.com/rezatg/gitc/internal/ai/generic"
+	"github.com/rezatg/gitc/internal/git"
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+// checkStatus is the outcome of a doctor check
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+	checkSkip
+)
+
+// icon returns the mark printed before a check
+func (s checkStatus) icon() string {
+	switch s {
+	case checkPass:
+		return "✅"
+	case checkWarn:
+		return "⚠️ "
+	case checkFail:
+		return "❌"
+	}
+	return "➖"
+}
+
+// check is a line of the doctor checklist
+type check struct {
+	status checkStatus
+	name   string
+	detail string
+}
+
+// checklist collects the results of the doctor checks
+type checklist []check
+
+func (l *checklist) add(status checkStatus, name, format string, args ...any) {
+	*l = append(*l, check{status, name, fmt.Sprintf(format, args...)})
+}
+
+// DoctorAction checks git, the configuration and the connection to the AI
+// provider, and prints a checklist. It exits with 1 if any check fails.
+func (a *App) DoctorAction(c *cli.Context) error {
+	var l checklist
+	a.checkGit(c.Context, &l)
+	if a.checkConfig(&l) {
+		a.checkProvider(c.Context, &l)
+	} else {
+		l.add(checkSkip, "provider", "skipped: the configuration could not be loaded")
+	}
+
+	return l.report()
+}
+
+// report prints the checklist and returns an exit error if any check failed
+func (l checklist) report() error {
+	failed := 0
+	for _, ch := range l {
+		fmt.Printf("%s %-12s %s\n", ch.status.icon(), ch.name, ch.detail)
+		if ch.status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n❌ %d of %d checks failed\n", failed, len(l))
+		return cli.Exit("", 1)
+	}
+	fmt.Println("\n✅ All checks passed")
+	return nil
+}
+
+// checkGit checks the git version and the state of the repository
+func (a *App) checkGit(ctx context.Context, l *checklist) {
+	version, err := git.GetVersion(ctx)
+	if err != nil {
+		l.add(checkFail, "git", "%v", err)
+		l.add(checkSkip, "repository", "skipped: git is not available")
+		return
+	}
+	if git.VersionAtLeast(version, git.MinVersion) {
+		l.add(checkPass, "git", "version %s", version)
+	} else {
+		l.add(checkFail, "git", "version %s is older than %s; upgrade git", version, git.MinVersion)
+	}
+
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		l.add(checkWarn, "repository", "not inside a git repository")
+		return
+	}
+	branch, err := git.GetCurrentBranch(ctx)
+	if err != nil {
+		l.add(checkFail, "repository", "%s: %v", root, err)
+		return
+	}
+	if branch == "" {
+		branch = "detached HEAD"
+	} else {
+		branch = "branch " + branch
+	}
+	files, err := git.GetStagedFiles(ctx, nil)
+	if err != nil {
+		l.add(checkFail, "repository", "%s on %s: %v", root, branch, err)
+		return
+	}
+	l.add(checkPass, "repository", "%s on %s, %d staged files", root, branch, len(files))
+}
+
+// checkConfig checks that the config files can be read and are valid, and
+// reports whether the configuration could be loaded
+func (a *App) checkConfig(l *checklist) bool {
+	path, err := config.Path()
+	if err != nil {
+		l.add(checkFail, "config file", "%v", err)
+		return false
+	}
+	if a.configErr != nil {
+		l.add(checkFail, "config file", "%s: %v", path, a.configErr)
+		return false
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		l.add(checkFail, "config file", "%v", err)
+	case runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0:
+		l.add(checkWarn, "config file", "%s is readable by other users (mode %04o); run chmod 600 %s", path, info.Mode().Perm(), path)
+	default:
+		l.add(checkPass, "config file", "%s (%s)", path, config.FormatOf(path))
+	}
+
+	if res := a.resolution; res.RepoFile != "" {
+		if len(res.Ignored) > 0 {
+			l.add(checkWarn, "repo config", "%s: ignoring %s, which may only be set globally", res.RepoFile, strings.Join(res.Ignored, ", "))
+		} else {
+			l.add(checkPass, "repo config", "%s", res.RepoFile)
+		}
+	}
+
+	if err := a.validateConfig(a.config); err != nil {
+		l.add(checkFail, "settings", "%v", err)
+	} else {
+		l.add(checkPass, "settings", "valid")
+	}
+	return true
+}
+
+// checkProvider reports the effective provider settings and checks the API
+// key, the proxy, a test request and the configured model
+func (a *App) checkProvider(ctx context.Context, l *checklist) {
+	cfg := a.config
+	origins := make(map[string]config.Origin)
+	for _, s := range a.resolution.Settings() {
+		origins[s.Key] = s.Origin
+	}
+	l.add(checkPass, "provider", "%s, from %s", cfg.Provider, origins["provider"])
+	l.add(checkPass, "model", "%s, from %s", cfg.Model, origins["model"])
+	l.add(checkPass, "URL", "%s, from %s", cfg.URL, origins["url"])
+
+	source, _ := cfg.KeySource()
+	key, err := cfg.ResolveAPIKey()
+	switch {
+	case err != nil:
+		l.add(checkFail, "API key", "%v", err)
+	case key == "":
+		l.add(checkFail, "API key", "not set; set api_key, api_key_cmd, api_key_file or api_key_env")
+	default:
+		l.add(checkPass, "API key", "%s in %s, from %s", maskSecret(key), source, origins[source])
+	}
+
+	if cfg.Proxy == "" {
+		l.add(checkSkip, "proxy", "not set")
+	} else {
+		address := proxyAddress(cfg.Proxy)
+		conn, err := net.DialTimeout("tcp", address, time.Duration(cfg.Timeout)*time.Second)
+		if err != nil {
+			l.add(checkFail, "proxy", "%s is unreachable: %v", address, err)
+		} else {
+			conn.Close()
+			l.add(checkPass, "proxy", "%s is reachable", address)
+		}
+	}
+
+	if key == "" {
+		l.add(checkSkip, "API request", "skipped: no API key")
+		l.add(checkSkip, "model list", "skipped: no API key")
+		return
+	}
+	provider, err := a.initAIProvider(&ai.Config{Provider: cfg.Provider, APIKey: key, Proxy: cfg.Proxy, URL: cfg.URL})
+	if err != nil {
+		l.add(checkFail, "API request", "%v", err)
+		return
+	}
+
+	// Each request gets the full timeout, so that a slow test request does not
+	// fail the model list
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	start := time.Now()
+	err = provider.Ping(pingCtx, cfg.Model, cfg.MaxRedirects)
+	cancel()
+	if err != nil {
+		l.add(checkFail, "API request", "%v", err)
+	} else {
+		l.add(checkPass, "API request", "answered in %s", time.Since(start).Round(time.Millisecond))
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	models, err := provider.ListModels(listCtx, cfg.MaxRedirects)
+	var statusErr *generic.StatusError
+	switch {
+	case errors.As(err, &statusErr) && statusErr.Code == 404:
+		l.add(checkWarn, "model list", "%s has no models endpoint; %s is not checked", cfg.Provider, cfg.Model)
+	case err != nil:
+		l.add(checkFail, "model list", "failed to list models: %v", err)
+	case slices.Contains(models, cfg.Model):
+		l.add(checkPass, "model list", "%s is available", cfg.Model)
+	default:
+		l.add(checkFail, "model list", "%s is not offered by %s (available: %s)", cfg.Model, cfg.Provider, summarizeModels(models))
+	}
+}
+
+// proxyAddress returns the host:port of a proxy given as a URL or as
+// [user:password@]host:port
+func proxyAddress(proxy string) string {
+	if u, err := url.Parse(proxy); err == nil && u.Host != "" {
+		if u.Port() != "" {
+			return u.Host
+		} else if u.Scheme == "https" {
+			return net.JoinHostPort(u.Hostname(), "443")
+		}
+		return net.JoinHostPort(u.Hostname(), "80")
+	}
+	if i := strings.LastIndex(proxy, "@"); i >= 0 {
+		return proxy[i+1:]
+	}
+	return proxy
+}
+
+// summarizeModels lists the first few models and how many more there are
+func summarizeModels(models []string) string {
+	const shown = 8
+	if len(models) == 0 {
+		return "none"
+	} else if len(models) <= shown {
+		return strings.Join(models, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(models[:shown], ", "), len(models)-shown)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rezatg/gitc/internal/feedback"
+	"github.com/rezatg/gitc/internal/index"
+	"github.com/rezatg/gitc/pkg/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// openFeedback loads the feedback store of the repository and resolves the
+// messages committed since the last run
+func (a *App) openFeedback(ctx context.Context) (*feedback.Store, string, error) {
+	path, err := feedback.Path(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	store, err := feedback.Load(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if changed, err := store.Resolve(ctx, a.config.Feedback.MaxCorrections); err != nil {
+		return nil, "", err
+	} else if changed {
+		if err := store.Save(path); err != nil {
+			return nil, "", err
+		}
+	}
+	return store, path, nil
+}
+
+// feedbackCorrections returns the recorded corrections most relevant to the
+// staged diff. Failing to read the store is reported and ignored.
+func (a *App) feedbackCorrections(ctx context.Context, diff string) []utils.Correction {
+	if a.config.Feedback.Mode != feedback.ModeLearn {
+		return nil
+	}
+
+	store, _, err := a.openFeedback(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ ignoring recorded feedback: %v\n", err)
+		return nil
+	}
+
+	var corrections []utils.Correction
+	for _, e := range store.Relevant(index.DiffTerms(diff), a.config.Feedback.Count) {
+		corrections = append(corrections, utils.Correction{Generated: e.Generated, Final: e.Final})
+	}
+	return corrections
+}
+
+// recordFeedback remembers a generated message for the staged changes so
+// that edits made to it before committing can be learned from. Failures are
+// reported and ignored.
+func (a *App) recordFeedback(ctx context.Context, diff, msg string) {
+	if a.config.Feedback.Mode != feedback.ModeLearn {
+		return
+	}
+
+	err := func() error {
+		fingerprint, err := a.gitService.WriteIndexTree(ctx)
+		if err != nil {
+			return err
+		}
+		store, path, err := a.openFeedback(ctx)
+		if err != nil {
+			return err
+		}
+		store.Record(fingerprint, msg, index.DiffTerms(diff))
+		return store.Save(path)
+	}()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ failed to record feedback: %v\n", err)
+	}
+}
+
+// FeedbackListAction prints the recorded corrections and the number of
+// generated messages not committed yet
+func (a *App) FeedbackListAction(c *cli.Context) error {
+	store, _, err := a.openFeedback(c.Context)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	corrections := store.Corrections()
+	if len(corrections) == 0 {
+		fmt.Println("No corrections recorded yet.")
+	}
+	for i, e := range corrections {
+		fmt.Printf("%d. %s\n", i+1, e.Created.Local().Format("2006-01-02 15:04"))
+		fmt.Printf("   generated: %s\n", strings.ReplaceAll(e.Generated, "\n", "\n              "))
+		fmt.Printf("   committed: %s\n", strings.ReplaceAll(e.Final, "\n", "\n              "))
+	}
+
+	if pending := len(store.Entries) - len(corrections); pending > 0 {
+		fmt.Printf("\n%d generated message(s) not committed yet\n", pending)
+	}
+	return nil
+}
+
+// FeedbackClearAction deletes the feedback store of the repository
+func (a *App) FeedbackClearAction(c *cli.Context) error {
+	path, err := feedback.Path(c.Context)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("❌ failed to clear feedback: %w", err)
+	}
+
+	fmt.Println("✅ Recorded feedback cleared")
+	return nil
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rezatg/gitc/internal/ai"
+	"github.com/rezatg/gitc/internal/git"
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/rezatg/gitc/pkg/utils"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// InitAction sets gitc up interactively. The global setup asks for the
+// provider, where its API key is read from, the model, the language, the
+// convention and Gitmoji; the repository setup only for the last three,
+// which the team shares. The answers are checked with a test request
+// before they are saved.
+func (a *App) InitAction(c *cli.Context) error {
+	if c.Bool("global") && c.Bool("repo") {
+		return cli.Exit("❌ --global and --repo cannot be used together", 2)
+	}
+
+	w := &wizard{}
+	repo := c.Bool("repo")
+	if !repo && !c.Bool("global") {
+		if _, err := git.GetRepoRoot(); err == nil {
+			repo = w.choose("Save the settings to", []string{
+				"the global config, used in every repository",
+				"the repository config, shared with the team",
+			}, 0) == 1
+		}
+	}
+
+	if repo {
+		return a.initRepo(c.Context, w)
+	}
+	return a.initGlobal(c.Context, w)
+}
+
+// initGlobal sets up the global config
+func (a *App) initGlobal(ctx context.Context, w *wizard) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("❌ failed to load config: %w", err)
+	}
+
+	provider := w.chooseProvider(cfg)
+	key := w.chooseKeySource(cfg, provider)
+	if w.err != nil {
+		return w.err
+	}
+
+	// Keep the model and URL, e.g. of a gateway, when the provider stays
+	model, url := provider.Model, provider.URL
+	if provider.Name == cfg.Provider {
+		model = cfg.Model
+		if _, ok := config.LookupProvider(provider.Name); ok {
+			url = cfg.URL
+		}
+	}
+	model = w.chooseModel(a.listModels(ctx, cfg, provider.Name, url, key), model)
+
+	before := *cfg
+	cfg.Provider, cfg.Model, cfg.URL = provider.Name, model, url
+	cfg.APIKey, cfg.APIKeyCmd, cfg.APIKeyFile, cfg.APIKeyEnv = key.APIKey, key.APIKeyCmd, key.APIKeyFile, key.APIKeyEnv
+	cfg.Language = w.askRequired("Commit message language", cfg.Language)
+	cfg.Preset = w.choosePreset(cfg.Preset)
+	cfg.UseGitmoji = w.yesNo("Add Gitmoji to commit messages?", cfg.UseGitmoji)
+	if w.err != nil {
+		return w.err
+	}
+
+	apiKey, err := key.ResolveAPIKey()
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	if !a.testSetup(ctx, cfg, apiKey) && !confirm("Save anyway?") {
+		return fmt.Errorf("❌ configuration not saved")
+	}
+
+	// The defaults of the answers are left unset, so that they keep following
+	// the provider or a later release
+	return a.updateConfig(func(file *config.Config) error {
+		defaults, _ := config.LookupProvider(provider.Name)
+		file.Provider = provider.Name
+		file.Model, file.URL = unlessDefault(model, defaults.Model), unlessDefault(url, defaults.URL)
+		file.APIKey, file.APIKeyCmd, file.APIKeyFile, file.APIKeyEnv = key.APIKey, key.APIKeyCmd, key.APIKeyFile, key.APIKeyEnv
+		if cfg.Language != before.Language {
+			file.Language = cfg.Language
+		}
+		if cfg.Preset != before.Preset {
+			file.Preset = cfg.Preset
+		}
+		if cfg.UseGitmoji != before.UseGitmoji {
+			file.UseGitmoji = cfg.UseGitmoji
+		}
+		return nil
+	})
+}
+
+// unlessDefault returns value, or an empty string if it is the default
+func unlessDefault(value, def string) string {
+	if value == def {
+		return ""
+	}
+	return value
+}
+
+// initRepo sets up the repository config
+func (a *App) initRepo(ctx context.Context, w *wizard) error {
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("❌ not inside a git repository")
+	}
+
+	cfg := *a.config
+	cfg.Language = w.askRequired("Commit message language", cfg.Language)
+	cfg.Preset = w.choosePreset(cfg.Preset)
+	cfg.UseGitmoji = w.yesNo("Add Gitmoji to commit messages?", cfg.UseGitmoji)
+	if w.err != nil {
+		return w.err
+	}
+	if err := a.validateConfig(&cfg); err != nil {
+		return fmt.Errorf("❌ invalid configuration: %w", err)
+	}
+
+	apiKey, err := cfg.ResolveAPIKey()
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	if !a.testSetup(ctx, &cfg, apiKey) && !confirm("Save anyway?") {
+		return fmt.Errorf("❌ configuration not saved")
+	}
+
+	path, err := config.SaveRepo(root, map[string]any{
+		"language":    cfg.Language,
+		"preset":      cfg.Preset,
+		"use_gitmoji": cfg.UseGitmoji,
+	})
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	fmt.Printf("✅ Saved %s; commit it to share the settings with your team\n", path)
+	return nil
+}
+
+// listModels returns the models offered by a provider, or none if they
+// cannot be listed
+func (a *App) listModels(ctx context.Context, cfg *config.Config, provider, url string, key config.Profile) []string {
+	probe := *cfg
+	probe.Provider, probe.URL = provider, url
+	probe.APIKey, probe.APIKeyCmd, probe.APIKeyFile, probe.APIKeyEnv = key.APIKey, key.APIKeyCmd, key.APIKeyFile, key.APIKeyEnv
+
+	models, err := a.fetchModels(ctx, &probe)
+	if err != nil {
+		fmt.Printf("⚠️ failed to list the models of %s: %v\n", provider, err)
+		return nil
+	}
+	return models
+}
+
+// testSetup validates the configuration and sends a test request with it,
+// reporting whether both succeeded
+func (a *App) testSetup(ctx context.Context, cfg *config.Config, apiKey string) bool {
+	if err := a.validateConfig(cfg); err != nil {
+		fmt.Printf("❌ invalid configuration: %v\n", err)
+		return false
+	}
+	if apiKey == "" {
+		fmt.Println("❌ the API key could not be read, so the setup was not tested")
+		return false
+	}
+
+	p, err := a.initAIProvider(&ai.Config{Provider: cfg.Provider, APIKey: apiKey, Proxy: cfg.Proxy, URL: cfg.URL})
+	if err == nil {
+		fmt.Printf("Testing %s with %s...\n", cfg.Provider, cfg.Model)
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Timeout)*time.Second)
+		defer cancel()
+		err = p.Ping(ctx, cfg.Model, cfg.MaxRedirects)
+	}
+	if err != nil {
+		fmt.Printf("❌ test request failed: %v\n", err)
+		return false
+	}
+	fmt.Println("✅ test request succeeded")
+	return true
+}
+
+// errInputEnded is reported when the input ends before the setup is complete
+var errInputEnded = errors.New("❌ setup aborted: the input ended")
+
+// wizard asks questions on the terminal. Once the input ends, every question
+// takes its default and err is set.
+type wizard struct {
+	err error
+}
+
+// ask asks a question and returns the trimmed answer, or def if it is empty
+func (w *wizard) ask(question, def string) string {
+	if w.err != nil {
+		return def
+	}
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		w.err = errInputEnded
+		return def
+	}
+	return cmp.Or(strings.TrimSpace(answer), def)
+}
+
+// askRequired asks a question until the answer, or def, is not empty
+func (w *wizard) askRequired(question, def string) string {
+	for w.err == nil {
+		if answer := w.ask(question, def); answer != "" {
+			return answer
+		}
+	}
+	return def
+}
+
+// yesNo asks a yes/no question
+func (w *wizard) yesNo(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for w.err == nil {
+		switch strings.ToLower(w.ask(question+" ("+hint+")", "")) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+	return def
+}
+
+// askSecret asks for a secret until the answer is not empty, without echoing
+// it when the input is a terminal
+func (w *wizard) askSecret(question string) string {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return w.askRequired(question, "")
+	}
+	for w.err == nil {
+		fmt.Printf("%s: ", question)
+		secret, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			w.err = errInputEnded
+			break
+		}
+		if answer := strings.TrimSpace(string(secret)); answer != "" {
+			return answer
+		}
+	}
+	return ""
+}
+
+// choose lists numbered options and returns the index of the chosen one
+func (w *wizard) choose(question string, options []string, def int) int {
+	fmt.Printf("%s:\n", question)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+	for w.err == nil {
+		n, err := strconv.Atoi(w.ask("Choose", strconv.Itoa(def+1)))
+		if err == nil && n >= 1 && n <= len(options) {
+			return n - 1
+		}
+		fmt.Printf("Enter a number from 1 to %d\n", len(options))
+	}
+	return def
+}
+
+// chooseProvider asks for a supported provider or the name and URL of
+// another OpenAI-compatible API
+func (w *wizard) chooseProvider(cfg *config.Config) config.ProviderInfo {
+	options := make([]string, 0, len(config.Providers)+1)
+	def := len(config.Providers)
+	for i, p := range config.Providers {
+		options = append(options, p.Title)
+		if p.Name == cfg.Provider {
+			def = i
+		}
+	}
+	options = append(options, "Another OpenAI-compatible API (e.g., a gateway or a local model)")
+
+	if i := w.choose("AI provider", options, def); i < len(config.Providers) {
+		return config.Providers[i]
+	}
+	other := config.ProviderInfo{KeyEnv: "AI_API_KEY"}
+	name, url := "", ""
+	if _, ok := config.LookupProvider(cfg.Provider); !ok {
+		name, url = cfg.Provider, cfg.URL
+	}
+	other.Name = w.askRequired("Provider name", name)
+	other.URL = w.askRequired("Chat completions URL", url)
+	return other
+}
+
+// chooseKeySource asks where the API key is read from. The current source,
+// if any, is offered first and kept by default while the provider stays.
+func (w *wizard) chooseKeySource(cfg *config.Config, provider config.ProviderInfo) config.Profile {
+	sources := []string{"api_key_env", "api_key_cmd", "api_key_file", "api_key"}
+	options := []string{
+		"an environment variable",
+		"a command printing it, e.g. a password manager",
+		"a file",
+		"the config file itself, in plain text (not recommended)",
+	}
+	def := 0
+	if source, _ := cfg.KeySource(); source != "" {
+		sources = append([]string{""}, sources...)
+		options = append([]string{"keep the current key, " + describeKeySource(cfg.Profile)}, options...)
+		if provider.Name != cfg.Provider {
+			def = 1 // the key of another provider is unlikely to work
+		}
+	}
+
+	var key config.Profile
+	switch sources[w.choose("Read the API key from", options, def)] {
+	case "":
+		key = config.Profile{APIKey: cfg.APIKey, APIKeyCmd: cfg.APIKeyCmd, APIKeyFile: cfg.APIKeyFile, APIKeyEnv: cfg.APIKeyEnv}
+	case "api_key_env":
+		key.APIKeyEnv = w.askRequired("Environment variable", cmp.Or(cfg.APIKeyEnv, provider.KeyEnv))
+	case "api_key_cmd":
+		key.APIKeyCmd = w.askRequired("Command", cfg.APIKeyCmd)
+	case "api_key_file":
+		key.APIKeyFile = w.askRequired("File", cfg.APIKeyFile)
+	case "api_key":
+		fmt.Println("⚠️ the key is saved unencrypted; anyone who can read the config file can use it")
+		key.APIKey = w.askSecret("API key")
+	}
+	return key
+}
+
+// describeKeySource describes where the API key of a profile is read from
+func describeKeySource(p config.Profile) string {
+	switch {
+	case p.APIKeyEnv != "":
+		return "read from $" + p.APIKeyEnv
+	case p.APIKeyCmd != "":
+		return "printed by " + p.APIKeyCmd
+	case p.APIKeyFile != "":
+		return "read from " + p.APIKeyFile
+	}
+	return "saved in the config file (" + maskSecret(p.APIKey) + ")"
+}
+
+// chooseModel asks for a model, by number from the models offered by the
+// provider when they are known, or by name; updateConfig warns about unknown
+// ones
+func (w *wizard) chooseModel(models []string, def string) string {
+	if len(models) == 0 {
+		return w.askRequired("Model", def)
+	}
+
+	fmt.Println("Models:")
+	for i, model := range models {
+		fmt.Printf("  %d) %s\n", i+1, model)
+	}
+	answer := w.askRequired("Model (number or name)", def)
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(models) {
+		return models[n-1]
+	}
+	return answer
+}
+
+// choosePreset asks for the convention preset
+func (w *wizard) choosePreset(current string) string {
+	options := make([]string, len(utils.Presets))
+	def := 0
+	for i, preset := range utils.Presets {
+		options[i] = fmt.Sprintf("%-12s %s", preset.Name, preset.Description)
+		if preset.Name == current {
+			def = i
+		}
+	}
+	return utils.Presets[w.choose("Commit message convention", options, def)].Name
+}
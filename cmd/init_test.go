@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/rezatg/gitc/pkg/config"
+)
+
+// ------------------- key source -------------------
+
+func TestChooseKeySource(t *testing.T) {
+	defer func(r *bufio.Reader) { stdin = r }(stdin)
+	openai, _ := config.LookupProvider("openai")
+	deepseek, _ := config.LookupProvider("deepseek")
+
+	cfg := config.DefaultConfig()
+	cfg.APIKeyCmd = "pass show openai"
+	tests := []struct {
+		provider config.ProviderInfo
+		input    string
+		want     config.Profile
+	}{
+		{openai, "\n", config.Profile{APIKeyCmd: "pass show openai"}},
+		{deepseek, "\n\n", config.Profile{APIKeyEnv: "DEEPSEEK_API_KEY"}},
+		{openai, "4\n~/.openai-key\n", config.Profile{APIKeyFile: "~/.openai-key"}},
+	}
+
+	for _, tt := range tests {
+		stdin = bufio.NewReader(strings.NewReader(tt.input))
+		w := &wizard{}
+		var key config.Profile
+		out, _ := captureStdout(t, func() error {
+			key = w.chooseKeySource(cfg, tt.provider)
+			return w.err
+		})
+		if key != tt.want {
+			t.Errorf("chooseKeySource(%s) with %q = %+v, want %+v", tt.provider.Name, tt.input, key, tt.want)
+		}
+		if !strings.Contains(out, "1) keep the current key, printed by pass show openai") {
+			t.Errorf("expected the current key to be offered first, got:\n%s", out)
+		}
+	}
+}
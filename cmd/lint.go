@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/rezatg/gitc/internal/git"
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/rezatg/gitc/pkg/utils"
+	"github.com/rezatg/gitc/pkg/validator"
+	"github.com/urfave/cli/v2"
+)
+
+// hookMarker identifies hooks installed by gitc so they can be replaced safely
+const hookMarker = "# installed by gitc"
+
+// commitMsgHook is the commit-msg hook script that lints every new commit
+const commitMsgHook = `#!/bin/sh
+` + hookMarker + `
+exec gitc lint "$1"
+`
+
+// lintResult is the outcome of linting a single message
+type lintResult struct {
+	Source     string                `json:"source"`
+	Header     string                `json:"header"`
+	Valid      bool                  `json:"valid"`
+	Ignored    bool                  `json:"ignored,omitempty"`
+	Violations []validator.Violation `json:"violations"`
+}
+
+// LintAction lints a commit message file, standard input, or a range of commits
+func (a *App) LintAction(c *cli.Context) error {
+	format := c.String("format")
+	if format != "text" && format != "json" {
+		return cli.Exit(fmt.Sprintf("❌ invalid format %q (expected text or json)", format), 2)
+	}
+
+	messages, err := lintInputs(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("❌ %v", err), 2)
+	}
+
+	rules, err := a.lintRules(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("❌ %v", err), 2)
+	}
+	results := make([]lintResult, 0, len(messages))
+	failed := 0
+	for _, msg := range messages {
+		result := lintResult{
+			Source:     msg.SHA,
+			Header:     validator.Parse(msg.Message).Header,
+			Violations: []validator.Violation{},
+		}
+		if validator.Ignored(msg.Message) {
+			result.Ignored = true
+		} else if violations := validator.Validate(msg.Message, rules); len(violations) > 0 {
+			result.Violations = violations
+		}
+		result.Valid = len(result.Violations) == 0
+		if !result.Valid {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if format == "json" {
+		data, err := sonic.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("❌ failed to encode results: %v", err), 2)
+		}
+		fmt.Println(string(data))
+	} else {
+		printLintResults(results)
+	}
+
+	if failed > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// lintRules builds the rules human written messages are checked against
+func (a *App) lintRules(c *cli.Context) (validator.Rules, error) {
+	name := c.String("preset")
+	if name == "" {
+		name = a.config.Preset
+	}
+	preset, ok := utils.LookupPreset(name)
+	if !ok {
+		return validator.Rules{}, fmt.Errorf("unknown preset %q (expected %s)", name, strings.Join(utils.PresetNames(), ", "))
+	}
+
+	convention := c.String("custom-convention")
+	if convention == "" {
+		convention = a.config.CustomConvention
+	}
+	conv, err := loadConvention(convention)
+	if err != nil {
+		return validator.Rules{}, err
+	}
+
+	return a.presetRules(preset, conv), nil
+}
+
+// presetRules builds the rules of a preset, narrowed by the custom
+// convention, that complete human written messages must follow
+func (a *App) presetRules(preset utils.Preset, conv *config.Convention) validator.Rules {
+	rules := validator.DefaultRules()
+	rules.Style = preset.Style
+	rules.Types = preset.ValidationTypes(a.config.Validation.Types)
+	rules.SubjectCase = preset.SubjectCase
+	rules.MaxHeaderLength = a.config.Validation.MaxHeaderLength
+	rules.MaxBodyLineLength = a.config.Validation.MaxBodyLineLength
+	rules.RequiredFooters = append(rules.RequiredFooters, preset.Footers...)
+	if preset.SignOff {
+		rules.RequiredFooters = append(rules.RequiredFooters, "Signed-off-by")
+	}
+
+	applyConventionRules(&rules, conv)
+	return rules
+}
+
+// lintInputs collects the messages to lint from --range, a file or stdin
+func lintInputs(c *cli.Context) ([]git.CommitMessage, error) {
+	if revRange := c.String("range"); revRange != "" {
+		if c.Args().Present() {
+			return nil, errors.New("--range cannot be combined with a message file")
+		}
+
+		commits, err := git.GetCommitMessages(c.Context, revRange)
+		if err != nil {
+			return nil, err
+		}
+		for i := range commits {
+			commits[i].SHA = commits[i].SHA[:min(len(commits[i].SHA), 12)]
+		}
+		return commits, nil
+	}
+
+	path := c.Args().First()
+	var (
+		data []byte
+		err  error
+	)
+	if path == "" || path == "-" {
+		path = "stdin"
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	return []git.CommitMessage{{SHA: path, Message: string(data)}}, nil
+}
+
+// printLintResults prints human readable lint results
+func printLintResults(results []lintResult) {
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Ignored:
+			fmt.Printf("⏭️  %s: %s (ignored)\n", result.Source, result.Header)
+		case result.Valid:
+			fmt.Printf("✅ %s: %s\n", result.Source, result.Header)
+		default:
+			failed++
+			fmt.Printf("❌ %s: %s\n", result.Source, result.Header)
+			for _, v := range result.Violations {
+				fmt.Printf("   - %s\n", v)
+			}
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d of %d message(s) failed linting\n", failed, len(results))
+	}
+}
+
+// HookInstallAction installs the commit-msg hook running `gitc lint`
+func (a *App) HookInstallAction(c *cli.Context) error {
+	path, err := git.GetHookPath(c.Context, "commit-msg")
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), hookMarker) {
+		if !c.Bool("force") {
+			return fmt.Errorf("❌ %s already exists; use --force to replace it (a backup is kept)", path)
+		}
+		if err := os.WriteFile(path+".bak", existing, 0755); err != nil {
+			return fmt.Errorf("❌ failed to back up existing hook: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("❌ failed to create hooks directory: %w", err)
+	} else if err := os.WriteFile(path, []byte(commitMsgHook), 0755); err != nil {
+		return fmt.Errorf("❌ failed to write hook: %w", err)
+	}
+
+	fmt.Printf("✅ commit-msg hook installed at %s\n", path)
+	return nil
+}
+
+// HookUninstallAction removes the commit-msg hook if it was installed by gitc
+func (a *App) HookUninstallAction(c *cli.Context) error {
+	path, err := git.GetHookPath(c.Context, "commit-msg")
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Println("ℹ️  No commit-msg hook installed")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("❌ failed to read hook: %w", err)
+	} else if !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("❌ %s was not installed by gitc; remove it manually", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("❌ failed to remove hook: %w", err)
+	}
+
+	// Restore a hook that was replaced with --force
+	if _, err := os.Stat(path + ".bak"); err == nil {
+		if err := os.Rename(path+".bak", path); err != nil {
+			return fmt.Errorf("❌ failed to restore previous hook: %w", err)
+		}
+	}
+
+	fmt.Println("✅ commit-msg hook removed")
+	return nil
+}
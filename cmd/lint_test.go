@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/rezatg/gitc/pkg/validator"
+	"github.com/urfave/cli/v2"
+)
+
+// newTestContext builds the context of a command invoked with the given
+// string and bool flags and arguments
+func newTestContext(t *testing.T, flags map[string]string, bools map[string]bool, args ...string) *cli.Context {
+	t.Helper()
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for name, value := range flags {
+		set.String(name, value, "")
+	}
+	for name, value := range bools {
+		set.Bool(name, value, "")
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = fn()
+	os.Stdout = stdout
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	return string(out), err
+}
+
+// exitCode returns the exit code an action error results in
+func exitCode(err error) int {
+	var exitErr cli.ExitCoder
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	} else if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// initTestRepo creates a repository in a temporary directory and changes into it
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if out, err := exec.Command("git", "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	return dir
+}
+
+// ------------------- lint -------------------
+
+func TestLintAction(t *testing.T) {
+	app := NewApp(nil, config.DefaultConfig())
+	dir := t.TempDir()
+
+	tests := []struct {
+		msg   string
+		code  int
+		rules []string
+	}{
+		{"fix: typo: readme\n", 0, nil},
+		{"Merge branch 'main' into feature\n", 0, nil},
+		{"feat: Added login.\n# comment\n", 1, []string{"subject-full-stop", "subject-imperative"}},
+	}
+
+	for i, tt := range tests {
+		path := filepath.Join(dir, "COMMIT_EDITMSG"+string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte(tt.msg), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		c := newTestContext(t, map[string]string{"format": "json", "range": ""}, nil, path)
+		out, err := captureStdout(t, func() error { return app.LintAction(c) })
+		if code := exitCode(err); code != tt.code {
+			t.Errorf("lint %q exited with %d, want %d (%v)", tt.msg, code, tt.code, err)
+		}
+
+		var results []lintResult
+		if err := sonic.Unmarshal([]byte(out), &results); err != nil || len(results) != 1 {
+			t.Fatalf("unexpected JSON output %q: %v", out, err)
+		}
+		if results[0].Source != path || results[0].Valid != (tt.code == 0) {
+			t.Errorf("unexpected result for %q: %+v", tt.msg, results[0])
+		}
+		if got := rulesOf(results[0].Violations); strings.Join(got, ",") != strings.Join(tt.rules, ",") {
+			t.Errorf("lint %q reported %v, want %v", tt.msg, got, tt.rules)
+		}
+	}
+
+	c := newTestContext(t, map[string]string{"format": "xml"}, nil)
+	if err := app.LintAction(c); exitCode(err) != 2 {
+		t.Errorf("expected exit code 2 for an invalid format, got %v", err)
+	}
+}
+
+// ------------------- hook -------------------
+
+func TestHookInstallUninstall(t *testing.T) {
+	dir := initTestRepo(t)
+	app := NewApp(nil, config.DefaultConfig())
+	path := filepath.Join(dir, ".git", "hooks", "commit-msg")
+	custom := "#!/bin/sh\necho custom\n"
+	if err := os.WriteFile(path, []byte(custom), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	install := func(force bool) error {
+		_, err := captureStdout(t, func() error {
+			return app.HookInstallAction(newTestContext(t, nil, map[string]bool{"force": force}))
+		})
+		return err
+	}
+	uninstall := func() error {
+		_, err := captureStdout(t, func() error { return app.HookUninstallAction(newTestContext(t, nil, nil)) })
+		return err
+	}
+
+	if err := install(false); err == nil {
+		t.Fatal("expected an existing hook not to be replaced without --force")
+	}
+	if err := uninstall(); err == nil || !strings.Contains(err.Error(), "not installed by gitc") {
+		t.Fatalf("expected a hook not installed by gitc to be kept, got %v", err)
+	}
+
+	if err := install(true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), hookMarker) {
+		t.Errorf("expected the installed hook to carry the marker, got %q", data)
+	}
+	if data, _ := os.ReadFile(path + ".bak"); string(data) != custom {
+		t.Errorf("expected the replaced hook to be backed up, got %q", data)
+	}
+	if err := install(false); err != nil {
+		t.Errorf("expected a hook installed by gitc to be replaced, got %v", err)
+	}
+
+	if err := uninstall(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != custom {
+		t.Errorf("expected the previous hook to be restored, got %q", data)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("expected the backup to be gone, got %v", err)
+	}
+}
+
+// rulesOf lists the rules of violations
+func rulesOf(violations []validator.Violation) []string {
+	rules := make([]string, len(violations))
+	for i, v := range violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/rezatg/gitc/internal/ai"
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/urfave/cli/v2"
+)
+
+// ModelsAction prints the models offered by the configured provider, using
+// its URL, proxy and API key
+func (a *App) ModelsAction(c *cli.Context) error {
+	cfg := a.config
+	models, err := a.fetchModels(c.Context, cfg)
+	if err != nil {
+		return fmt.Errorf("❌ failed to list the models of %s: %w", cfg.Provider, err)
+	}
+
+	for _, model := range models {
+		fmt.Println(model)
+	}
+	if !slices.Contains(models, cfg.Model) {
+		fmt.Fprintf(os.Stderr, "⚠️ the configured model %q is not among them\n", cfg.Model)
+	}
+	return nil
+}
+
+// fetchModels lists the models offered by the provider of a config
+func (a *App) fetchModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	apiKey, err := cfg.ResolveAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key: %w", err)
+	} else if apiKey == "" {
+		return nil, fmt.Errorf("API key is required (set api_key, api_key_cmd, api_key_file or api_key_env)")
+	}
+
+	provider, err := a.initAIProvider(&ai.Config{Provider: cfg.Provider, APIKey: apiKey, Proxy: cfg.Proxy, URL: cfg.URL})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+	return provider.ListModels(ctx, cfg.MaxRedirects)
+}
+
+// warnUnknownModel warns when the provider does not offer the configured
+// model. Failures to list the models, e.g. of providers without a models
+// endpoint, are not reported.
+func (a *App) warnUnknownModel(ctx context.Context, cfg *config.Config) {
+	models, err := a.fetchModels(ctx, cfg)
+	if err != nil || len(models) == 0 || slices.Contains(models, cfg.Model) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⚠️ %s does not offer the model %q; run gitc models to list the available ones\n", cfg.Provider, cfg.Model)
+}
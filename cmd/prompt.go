@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rezatg/gitc/pkg/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// PromptShowAction prints the system and user prompts that would be sent for
+// the staged changes, together with the templates they were rendered from
+func (a *App) PromptShowAction(c *cli.Context) error {
+	diff, err := a.gitService.GetDiff(c.Context)
+	if err != nil {
+		return fmt.Errorf("❌ failed to get git diff: %v", err)
+	} else if diff == "" {
+		return fmt.Errorf("❌ nothing staged for commit")
+	}
+
+	cfg, err := a.ConfigureAI(c)
+	if err != nil {
+		return fmt.Errorf("❌ failed to build AI config: %w", err)
+	}
+
+	files, err := a.gitService.GetStagedFiles(c.Context)
+	if err != nil {
+		return fmt.Errorf("❌ failed to list staged files: %w", err)
+	}
+
+	opts, err := a.messageOptions(c.Context, cfg, files, diff)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	promptOpts := opts.PromptOptions()
+	system, err := utils.GetSystemPrompt(promptOpts)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	prompt, err := utils.GetPromptForSingleCommit(diff, promptOpts)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	fmt.Printf("# %s (%s)\n%s\n\n", utils.TemplateSystem, opts.Templates.Source(utils.TemplateSystem), system)
+	fmt.Printf("# %s (%s)\n%s\n", utils.TemplateCommit, opts.Templates.Source(utils.TemplateCommit), prompt)
+	return nil
+}
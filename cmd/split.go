@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/rezatg/gitc/internal/ai"
+	"github.com/rezatg/gitc/internal/git"
+	"github.com/rezatg/gitc/pkg/validator"
+	"github.com/urfave/cli/v2"
+)
+
+// maxHunkLinesInPrompt limits how many lines of a single hunk are shown to the AI
+const maxHunkLinesInPrompt = 80
+
+// splitUnit is an independently stageable part of the staged patch: a single
+// hunk, or a whole file when it cannot be split (binary files, mode changes).
+type splitUnit struct {
+	file int
+	hunk int // -1 for the whole file
+}
+
+// splitCommit is a planned commit with the units it stages
+type splitCommit struct {
+	units   []splitUnit
+	message string
+}
+
+// SplitAction splits the staged changes into several logical commits
+func (a *App) SplitAction(c *cli.Context) error {
+	patch, err := a.gitService.GetStagedPatch(c.Context)
+	if err != nil {
+		return fmt.Errorf("❌ failed to get staged changes: %w", err)
+	}
+
+	files := git.ParsePatch(patch)
+	if len(files) == 0 {
+		return fmt.Errorf("❌ nothing staged for commit")
+	}
+
+	// Excluded files, e.g. lock files, are committed but not shown to the AI
+	included, err := a.gitService.GetStagedFiles(c.Context)
+	if err != nil {
+		return fmt.Errorf("❌ failed to get staged changes: %w", err)
+	}
+	units, excluded := partitionUnits(files, splitUnits(files), included)
+	if len(units) == 0 {
+		fmt.Println("ℹ️  Only excluded files are staged; nothing to split.")
+		return nil
+	}
+
+	cfg, err := a.ConfigureAI(c)
+	if err != nil {
+		return fmt.Errorf("❌ failed to build AI config: %w", err)
+	}
+
+	commits, err := a.planSplit(c.Context, cfg, files, units)
+	if err != nil {
+		return fmt.Errorf("❌ failed to plan commits: %w", err)
+	}
+	attachExcluded(commits, files, excluded)
+
+	printSplitPlan(files, commits)
+	if len(commits) < 2 {
+		fmt.Println("ℹ️  The staged changes form a single logical commit; nothing to split.")
+		return nil
+	}
+
+	if !c.Bool("yes") && !confirm("Create these commits?") {
+		fmt.Println("Aborted; staged changes were left untouched.")
+		return nil
+	}
+
+	if err := a.applySplit(c.Context, files, commits, lookupPreset(cfg.Preset).SignOff); err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	fmt.Printf("✅ Created %d commits\n", len(commits))
+	return nil
+}
+
+// planSplit asks the AI provider to cluster the units into commits and
+// post-processes the proposed messages.
+func (a *App) planSplit(ctx context.Context, cfg *ai.Config, files []git.FilePatch, units []splitUnit) ([]splitCommit, error) {
+	provider, err := a.initAIProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize AI provider: %w", err)
+	}
+
+	// Scope and type are inferred per commit below, so no files are passed here
+	opts, err := a.messageOptions(ctx, cfg, nil, buildPatch(files, units))
+	if err != nil {
+		return nil, err
+	}
+	opts.Files = commitFiles(files, units)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	groups, err := provider.GenerateCommitPlan(ctx, describeUnits(files, units), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := normalizePlan(groups, units)
+	if err != nil {
+		return nil, err
+	}
+
+	// Infer scope and type per commit from the files it touches
+	for i := range commits {
+		groupOpts := opts
+		groupFiles := commitFiles(files, commits[i].units)
+		groupOpts.Scope, groupOpts.ScopeRequired = a.resolveScope(cfg, groupFiles)
+		groupOpts.TypeHint, groupOpts.TypeRequired = a.resolveTypeHint(cfg, groupFiles)
+		message := commits[i].message
+		if a.config.Validation.Mode != validator.ModeOff {
+			message = enforceHeader(message, groupOpts)
+			if rules := a.validationRules(groupOpts); len(validator.Validate(message, rules)) > 0 {
+				message = validator.Fix(message, rules)
+			}
+		}
+		commits[i].message = a.finalizeMessage(message, cfg, groupOpts)
+	}
+
+	return commits, nil
+}
+
+// applySplit unstages everything and stages and commits each group in order.
+// If anything fails, the index is restored so that all changes not yet
+// committed are staged again.
+func (a *App) applySplit(ctx context.Context, files []git.FilePatch, commits []splitCommit, signOff bool) error {
+	tree, err := a.gitService.WriteIndexTree(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := a.gitService.UnstageAll(ctx); err != nil {
+		return err
+	}
+
+	for i, commit := range commits {
+		err := a.gitService.ApplyCached(ctx, buildPatch(files, commit.units))
+		if err == nil {
+			err = a.gitService.Commit(ctx, commit.message, signOff)
+		}
+		if err != nil {
+			if restoreErr := a.gitService.RestoreIndexTree(ctx, tree); restoreErr != nil {
+				return fmt.Errorf("commit %d of %d failed: %v (and %v)", i+1, len(commits), err, restoreErr)
+			}
+			return fmt.Errorf("commit %d of %d failed, remaining changes are staged again: %w", i+1, len(commits), err)
+		}
+	}
+
+	return nil
+}
+
+// splitUnits enumerates the stageable units of the parsed patch
+func splitUnits(files []git.FilePatch) []splitUnit {
+	var units []splitUnit
+	for i, file := range files {
+		if file.Binary || len(file.Hunks) == 0 {
+			units = append(units, splitUnit{file: i, hunk: -1})
+			continue
+		}
+		for h := range file.Hunks {
+			units = append(units, splitUnit{file: i, hunk: h})
+		}
+	}
+	return units
+}
+
+// partitionUnits separates the units of the files that are left out of the
+// diff shown to the AI provider, i.e. those not among the included paths
+func partitionUnits(files []git.FilePatch, units []splitUnit, included []string) (shown, excluded []splitUnit) {
+	for _, unit := range units {
+		if slices.Contains(included, files[unit.file].Path) {
+			shown = append(shown, unit)
+		} else {
+			excluded = append(excluded, unit)
+		}
+	}
+	return shown, excluded
+}
+
+// attachExcluded adds the excluded units to the first commit touching a file
+// in the same directory, as a lock file belongs with its manifest, or else to
+// the last commit
+func attachExcluded(commits []splitCommit, files []git.FilePatch, excluded []splitUnit) {
+	for _, unit := range excluded {
+		target := len(commits) - 1
+		dir := path.Dir(files[unit.file].Path)
+		for i, commit := range commits {
+			if slices.ContainsFunc(commit.units, func(u splitUnit) bool { return path.Dir(files[u.file].Path) == dir }) {
+				target = i
+				break
+			}
+		}
+		commits[target].units = append(commits[target].units, unit)
+	}
+}
+
+// describeUnits renders the numbered units for the AI prompt
+func describeUnits(files []git.FilePatch, units []splitUnit) string {
+	var builder strings.Builder
+
+	for i, unit := range units {
+		file := files[unit.file]
+		switch {
+		case file.Binary:
+			fmt.Fprintf(&builder, "[%d] %s (binary file)\n", i+1, file.Path)
+		case unit.hunk < 0:
+			fmt.Fprintf(&builder, "[%d] %s (%s)\n", i+1, file.Path, strings.Join(file.Header[1:], "; "))
+		default:
+			hunk := file.Hunks[unit.hunk]
+			fmt.Fprintf(&builder, "[%d] %s\n%s\n", i+1, file.Path, hunk.Header)
+			for j, line := range hunk.Lines {
+				if j == maxHunkLinesInPrompt {
+					fmt.Fprintf(&builder, "... %d more lines\n", len(hunk.Lines)-j)
+					break
+				}
+				builder.WriteString(line + "\n")
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	return strings.TrimSpace(builder.String())
+}
+
+// normalizePlan maps the 1-based hunk IDs of the AI plan to units, dropping
+// duplicates and unknown IDs. Units left out by the AI are added to the last
+// commit so that no staged change is lost.
+func normalizePlan(groups []ai.CommitGroup, units []splitUnit) ([]splitCommit, error) {
+	assigned := make([]bool, len(units))
+	var commits []splitCommit
+
+	for _, group := range groups {
+		message := strings.TrimSpace(group.Message)
+		if message == "" {
+			return nil, fmt.Errorf("commit plan contains a group without a message")
+		}
+
+		commit := splitCommit{message: message}
+		for _, id := range group.Hunks {
+			if id < 1 || id > len(units) || assigned[id-1] {
+				continue
+			}
+			assigned[id-1] = true
+			commit.units = append(commit.units, units[id-1])
+		}
+		if len(commit.units) > 0 {
+			commits = append(commits, commit)
+		}
+	}
+
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("commit plan does not reference any staged hunk")
+	}
+
+	for i, ok := range assigned {
+		if !ok {
+			last := &commits[len(commits)-1]
+			last.units = append(last.units, units[i])
+		}
+	}
+
+	return commits, nil
+}
+
+// buildPatch renders the patch that stages the given units
+func buildPatch(files []git.FilePatch, units []splitUnit) string {
+	hunks := make(map[int][]int)
+	var order []int
+
+	for _, unit := range units {
+		if _, seen := hunks[unit.file]; !seen {
+			order = append(order, unit.file)
+			hunks[unit.file] = nil
+		}
+		if unit.hunk >= 0 {
+			hunks[unit.file] = append(hunks[unit.file], unit.hunk)
+		}
+	}
+
+	var builder strings.Builder
+	for _, i := range order {
+		if len(hunks[i]) == 0 {
+			builder.WriteString(files[i].String())
+			continue
+		}
+
+		// Keep hunks in file order so line offsets stay consistent
+		slices.Sort(hunks[i])
+		builder.WriteString(files[i].Patch(hunks[i]))
+	}
+
+	return builder.String()
+}
+
+// commitFiles lists the distinct file paths touched by the given units
+func commitFiles(files []git.FilePatch, units []splitUnit) []string {
+	var paths []string
+	seen := make(map[int]bool)
+	for _, unit := range units {
+		if !seen[unit.file] {
+			seen[unit.file] = true
+			paths = append(paths, files[unit.file].Path)
+		}
+	}
+	return paths
+}
+
+// printSplitPlan shows the planned commits and the files they touch
+func printSplitPlan(files []git.FilePatch, commits []splitCommit) {
+	fmt.Printf("📋 Proposed %d commit(s):\n", len(commits))
+	for i, commit := range commits {
+		fmt.Printf("\n%d. %s\n", i+1, strings.ReplaceAll(commit.message, "\n", "\n   "))
+		for _, path := range commitFiles(files, commit.units) {
+			fmt.Printf("   • %s\n", path)
+		}
+	}
+	fmt.Println()
+}
+
+// stdin reads the answers to questions asked on the terminal; it is shared so
+// that no buffered input is lost between questions
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on the terminal, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := stdin.ReadString('\n')
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rezatg/gitc/internal/ai"
+	"github.com/rezatg/gitc/internal/git"
+)
+
+// testPatch has two hunks in api/handler.go, a binary file and a lock file
+const testPatch = `diff --git a/api/handler.go b/api/handler.go
+index 1111111..2222222 100644
+--- a/api/handler.go
++++ b/api/handler.go
+@@ -1,3 +1,3 @@
+ package api
+-const a = 1
++const a = 2
+@@ -10,3 +10,3 @@ func Handle() {
+ 	x := 1
+-	y := 2
++	y := 3
+diff --git a/assets/logo.png b/assets/logo.png
+index 3333333..4444444 100644
+GIT binary patch
+literal 4
+LcmZQz0000100001
+
+diff --git a/api/go.sum b/api/go.sum
+index 5555555..6666666 100644
+--- a/api/go.sum
++++ b/api/go.sum
+@@ -1 +1 @@
+-old
++new
+`
+
+// ------------------- normalizePlan -------------------
+
+func TestNormalizePlan(t *testing.T) {
+	units := splitUnits(git.ParsePatch(testPatch))
+	if len(units) != 4 || units[2] != (splitUnit{file: 1, hunk: -1}) {
+		t.Fatalf("unexpected units: %+v", units)
+	}
+
+	commits, err := normalizePlan([]ai.CommitGroup{
+		{Message: "feat(api): bump a", Hunks: []int{1, 1, 9}},
+		{Message: "chore: update logo", Hunks: []int{3, 1}},
+		{Message: "docs: nothing", Hunks: []int{0}},
+	}, units)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Duplicates and unknown IDs are dropped, empty groups removed, and the
+	// units left out are added to the last commit
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %+v", commits)
+	}
+	if !slices.Equal(commits[0].units, []splitUnit{units[0]}) {
+		t.Errorf("unexpected units of the first commit: %+v", commits[0].units)
+	}
+	if !slices.Equal(commits[1].units, []splitUnit{units[2], units[1], units[3]}) {
+		t.Errorf("unexpected units of the last commit: %+v", commits[1].units)
+	}
+
+	if _, err := normalizePlan([]ai.CommitGroup{{Message: " ", Hunks: []int{1}}}, units); err == nil {
+		t.Error("expected error for a group without a message")
+	}
+	if _, err := normalizePlan([]ai.CommitGroup{{Message: "fix: x", Hunks: []int{7}}}, units); err == nil {
+		t.Error("expected error for a plan without known hunks")
+	}
+}
+
+// ------------------- buildPatch -------------------
+
+func TestBuildPatch(t *testing.T) {
+	files := git.ParsePatch(testPatch)
+
+	// Hunks are kept in file order and whole-file units render the file
+	got := buildPatch(files, []splitUnit{{file: 0, hunk: 1}, {file: 1, hunk: -1}, {file: 0, hunk: 0}})
+	want := files[0].String() + files[1].String()
+	if got != want {
+		t.Errorf("unexpected patch:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = buildPatch(files, []splitUnit{{file: 0, hunk: 1}})
+	want = "diff --git a/api/handler.go b/api/handler.go\nindex 1111111..2222222 100644\n" +
+		"--- a/api/handler.go\n+++ b/api/handler.go\n" +
+		"@@ -10,3 +10,3 @@ func Handle() {\n \tx := 1\n-\ty := 2\n+\ty := 3\n"
+	if got != want {
+		t.Errorf("unexpected single hunk patch:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// ------------------- excluded files -------------------
+
+func TestAttachExcluded(t *testing.T) {
+	files := git.ParsePatch(testPatch)
+	units, excluded := partitionUnits(files, splitUnits(files), []string{"api/handler.go", "assets/logo.png"})
+	if len(units) != 3 || !slices.Equal(excluded, []splitUnit{{file: 2, hunk: 0}}) {
+		t.Fatalf("unexpected partition: %+v / %+v", units, excluded)
+	}
+	if description := describeUnits(files, units); strings.Contains(description, "go.sum") {
+		t.Errorf("expected the excluded file not to be described: %s", description)
+	}
+
+	commits := []splitCommit{
+		{message: "chore: update logo", units: []splitUnit{units[2]}},
+		{message: "feat(api): bump a", units: units[:2]},
+	}
+	attachExcluded(commits, files, excluded)
+	if !slices.Contains(commits[1].units, excluded[0]) {
+		t.Errorf("expected the lock file to join the commit of its directory: %+v", commits)
+	}
+}
@@ -4,8 +4,11 @@ go 1.25.1
 
 require (
 	github.com/bytedance/sonic v1.14.1
+	github.com/pelletier/go-toml/v2 v2.4.3
 	github.com/urfave/cli/v2 v2.27.7
 	github.com/valyala/fasthttp v1.65.0
+	golang.org/x/term v0.34.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -3,6 +3,9 @@ package ai
 import (
 	"context"
 	"time"
+
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/rezatg/gitc/pkg/utils"
 )
 
 // AIProvider defines the interface for AI providers
@@ -10,6 +13,18 @@ type AIProvider interface {
 	GenerateCommitMessage(
 		ctx context.Context, diff string, opts MessageOptions,
 	) (string, error)
+	GenerateCommitPlan(
+		ctx context.Context, hunks string, opts MessageOptions,
+	) ([]CommitGroup, error)
+	Ping(ctx context.Context, model string, maxRedirects int) error
+	ListModels(ctx context.Context, maxRedirects int) ([]string, error)
+}
+
+// CommitGroup is one logical commit proposed when splitting staged changes.
+// Hunks refers to the numbered hunks passed to GenerateCommitPlan.
+type CommitGroup struct {
+	Hunks   []int  `json:"hunks"`
+	Message string `json:"message"`
 }
 
 // Config holds AI provider configuration
@@ -23,17 +38,61 @@ type Config struct {
 	Language         string
 	CommitType       string
 	CustomConvention string
+	Convention       *config.Convention
+	Preset           string
 	MaxRedirects     int
 	UseGitmoji       bool
+	TicketPattern    string
+	TicketPlacement  string
+	Scope            string
 
 	Proxy string
 }
 
 type MessageOptions struct {
-	Model            string
-	Language         string
-	CommitType       string
-	CustomConvention string
-	MaxLength        int
-	MaxRedirects     int
+	Templates     *utils.PromptTemplates
+	Preset        string
+	Files         []string
+	Branch        string
+	Model         string
+	Language      string
+	CommitType    string
+	Convention    *config.Convention
+	MaxLength     int
+	MaxRedirects  int
+	Tickets       []string
+	Scope         string
+	ScopeRequired bool
+	TypeHint      string
+	TypeRequired  bool
+	Types         []string
+	Examples      []string
+	Corrections   []utils.Correction
+
+	// Set when re-prompting after the previous message failed validation
+	Previous   string
+	Violations []string
+}
+
+// PromptOptions maps the message options onto the prompt options
+func (o MessageOptions) PromptOptions() utils.PromptOptions {
+	return utils.PromptOptions{
+		Templates:     o.Templates,
+		Preset:        o.Preset,
+		Files:         o.Files,
+		Branch:        o.Branch,
+		Language:      o.Language,
+		CommitType:    o.CommitType,
+		Convention:    o.Convention,
+		Tickets:       o.Tickets,
+		Scope:         o.Scope,
+		ScopeRequired: o.ScopeRequired,
+		TypeHint:      o.TypeHint,
+		TypeRequired:  o.TypeRequired,
+		Types:         o.Types,
+		Examples:      o.Examples,
+		Corrections:   o.Corrections,
+		Previous:      o.Previous,
+		Violations:    o.Violations,
+	}
 }
@@ -4,23 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/rezatg/gitc/internal/ai"
+	"github.com/rezatg/gitc/pkg/config"
 	"github.com/rezatg/gitc/pkg/utils"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpproxy"
 )
 
-// Default URLs for supported providers
-const (
-	defaultOpenAIURL   = "https://api.openai.com/v1/chat/completions"
-	defaultGrokURL     = "https://api.x.ai/v1/chat/completions"
-	defaultDeepSeekURL = "https://api.deepseek.com/v1/chat/completions"
-	systemPrompt       = "You are an AI assistant that generates concise and meaningful Git commit messages."
-)
-
 // GenericProvider implements the AIProvider interface for OpenAI-compatible APIs
 type GenericProvider struct {
 	apiKey   string
@@ -35,16 +31,11 @@ func NewGenericProvider(apiKey, proxy, url, provider string) (*GenericProvider,
 		return nil, errors.New("API key is required")
 	}
 	if url == "" {
-		switch provider {
-		case "openai":
-			url = defaultOpenAIURL
-		case "grok":
-			url = defaultGrokURL
-		case "deepseek":
-			url = defaultDeepSeekURL
-		default:
+		p, ok := config.LookupProvider(provider)
+		if !ok {
 			return nil, fmt.Errorf("no default URL for provider: %s", provider)
 		}
+		url = p.URL
 	}
 
 	client := &fasthttp.Client{
@@ -87,7 +78,53 @@ type Message struct {
 // GenerateCommitMessage generates a commit message using the API
 func (p *GenericProvider) GenerateCommitMessage(ctx context.Context, diff string, opts ai.MessageOptions) (string, error) {
 	// Adjust prompt based on provider if needed
-	prompt := utils.GetPromptForSingleCommit(diff, opts.CommitType, opts.CustomConvention, opts.Language)
+	prompt, err := utils.GetPromptForSingleCommit(diff, opts.PromptOptions())
+	if err != nil {
+		return "", err
+	}
+
+	commitMessage, err := p.complete(ctx, prompt, opts)
+	if err != nil {
+		return "", err
+	} else if commitMessage == "" {
+		return "", fmt.Errorf("empty commit message generated by %s", p.provider)
+	}
+
+	return commitMessage, nil
+}
+
+// GenerateCommitPlan asks the API to cluster numbered hunks into logical commits
+func (p *GenericProvider) GenerateCommitPlan(ctx context.Context, hunks string, opts ai.MessageOptions) ([]ai.CommitGroup, error) {
+	prompt, err := utils.GetPromptForCommitPlan(hunks, opts.PromptOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	// A plan holds several messages, so allow a larger response
+	opts.MaxLength = max(2048, opts.MaxLength*4)
+	content, err := p.complete(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan struct {
+		Commits []ai.CommitGroup `json:"commits"`
+	}
+	if err := sonic.Unmarshal([]byte(stripCodeFence(content)), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse commit plan from %s: %v", p.provider, err)
+	} else if len(plan.Commits) == 0 {
+		return nil, fmt.Errorf("empty commit plan generated by %s", p.provider)
+	}
+
+	return plan.Commits, nil
+}
+
+// complete sends a single prompt to the API and returns the trimmed reply
+func (p *GenericProvider) complete(ctx context.Context, prompt string, opts ai.MessageOptions) (string, error) {
+	systemPrompt, err := utils.GetSystemPrompt(opts.PromptOptions())
+	if err != nil {
+		return "", err
+	}
 
 	reqBody := Request{
 		Model: opts.Model,
@@ -105,45 +142,140 @@ func (p *GenericProvider) GenerateCommitMessage(ctx context.Context, diff string
 		return "", fmt.Errorf("failed to encode JSON: %v", err)
 	}
 
+	body, err := p.send(ctx, fasthttp.MethodPost, p.url, jsonData, opts.MaxRedirects)
+	if err != nil {
+		return "", err
+	}
+
+	var res Response
+	if err = sonic.Unmarshal(body, &res); err != nil {
+		return "", fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	if res.Error.Message != "" {
+		return "", fmt.Errorf("API error from %s: %s", p.provider, res.Error.Message)
+	} else if len(res.Choices) == 0 {
+		return "", fmt.Errorf("no response from %s", p.provider)
+	}
+
+	return strings.TrimSpace(res.Choices[0].Message.Content), nil
+}
+
+// Ping sends the smallest possible completion request, to check the key,
+// the URL and the model
+func (p *GenericProvider) Ping(ctx context.Context, model string, maxRedirects int) error {
+	jsonData, err := sonic.Marshal(Request{
+		Model:     model,
+		Messages:  []Message{{"user", "ping"}},
+		MaxTokens: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+
+	_, err = p.send(ctx, fasthttp.MethodPost, p.url, jsonData, maxRedirects)
+	return err
+}
+
+// ListModels returns the IDs of the models available from the provider's
+// models endpoint, sorted
+func (p *GenericProvider) ListModels(ctx context.Context, maxRedirects int) ([]string, error) {
+	body, err := p.send(ctx, fasthttp.MethodGet, modelsURL(p.url), nil, maxRedirects)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := sonic.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse models from %s: %v", p.provider, err)
+	}
+
+	models := make([]string, 0, len(res.Data))
+	for _, model := range res.Data {
+		models = append(models, model.ID)
+	}
+	slices.Sort(models)
+	return models, nil
+}
+
+// send makes an authenticated request to the API, within the deadline of the
+// context if it has one, and returns the body of a successful response
+func (p *GenericProvider) send(ctx context.Context, method, url string, body []byte, maxRedirects int) ([]byte, error) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI(p.url)
-	req.Header.SetMethod("POST")
+	req.SetRequestURI(url)
+	req.Header.SetMethod(method)
 	req.Header.Set("Authorization", "Bearer "+p.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.SetBody(jsonData)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.SetBody(body)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		req.SetTimeout(time.Until(deadline))
+	}
 
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 
-	if err = p.client.DoRedirects(req, resp, opts.MaxRedirects); err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
-	}
-
-	var res Response
-	if err = sonic.Unmarshal(resp.Body(), &res); err != nil {
-		return "", fmt.Errorf("failed to parse response: %v", err)
+	if err := p.client.DoRedirects(req, resp, maxRedirects); err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
 	}
 
 	if statusCode := resp.StatusCode(); statusCode != fasthttp.StatusOK {
-		if res.Error.Message != "" {
-			return "", fmt.Errorf("API error [%d] from %s: %s", statusCode, p.provider, res.Error.Message)
+		var res Response
+		if sonic.Unmarshal(resp.Body(), &res) == nil && res.Error.Message != "" {
+			return nil, &StatusError{Code: statusCode, Provider: p.provider, Message: res.Error.Message}
 		}
 
-		return "", fmt.Errorf("API returned status %d from %s: %s", statusCode, p.provider, resp.Body())
+		return nil, &StatusError{Code: statusCode, Provider: p.provider, Body: string(resp.Body())}
 	}
 
-	if res.Error.Message != "" {
-		return "", fmt.Errorf("API error from %s: %s", p.provider, res.Error.Message)
-	} else if len(res.Choices) == 0 {
-		return "", fmt.Errorf("no response from %s", p.provider)
+	return slices.Clone(resp.Body()), nil
+}
+
+// StatusError is returned for API responses with a status other than 200 OK
+type StatusError struct {
+	Code     int
+	Provider string
+	Message  string // error message of the API, if any
+	Body     string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("API error [%d] from %s: %s", e.Code, e.Provider, e.Message)
 	}
+	return fmt.Sprintf("API returned status %d from %s: %s", e.Code, e.Provider, e.Body)
+}
 
-	commitMessage := strings.TrimSpace(res.Choices[0].Message.Content)
-	if commitMessage == "" {
-		return "", fmt.Errorf("empty commit message generated by %s", p.provider)
+// modelsURL derives the models endpoint from the chat completions URL, e.g.
+// https://api.openai.com/v1/models from .../v1/chat/completions, keeping its
+// query. Other URLs are taken as the base of the API.
+func modelsURL(chatURL string) string {
+	u, err := url.Parse(chatURL)
+	if err != nil {
+		return strings.TrimSuffix(strings.TrimRight(chatURL, "/"), "/chat/completions") + "/models"
 	}
+	u.Path = strings.TrimSuffix(strings.TrimRight(u.Path, "/"), "/chat/completions") + "/models"
+	u.RawPath = ""
+	return u.String()
+}
 
-	return commitMessage, nil
+// stripCodeFence removes a Markdown code fence some models wrap JSON replies in
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+
+	content = strings.TrimPrefix(content, "```")
+	if i := strings.Index(content, "\n"); i >= 0 {
+		content = content[i+1:] // drop the language tag line
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
 }
@@ -0,0 +1,73 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// ------------------- models -------------------
+
+func TestModelsURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.openai.com/v1/chat/completions", "https://api.openai.com/v1/models"},
+		{"https://api.openai.com/v1/chat/completions/", "https://api.openai.com/v1/models"},
+		{"http://localhost:11434/v1", "http://localhost:11434/v1/models"},
+		{"http://localhost:11434/v1/", "http://localhost:11434/v1/models"},
+		{"https://gw.example.com/openai/chat/completions?api-version=2024-06-01", "https://gw.example.com/openai/models?api-version=2024-06-01"},
+	}
+
+	for _, tt := range tests {
+		if got := modelsURL(tt.url); got != tt.want {
+			t.Errorf("modelsURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer sk-test":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "invalid API key"}}`))
+		case r.URL.Path == "/v1/models":
+			w.Write([]byte(`{"object": "list", "data": [{"id": "gpt-4o-mini"}, {"id": "gpt-4.1"}, {"id": "gpt-4o"}]}`))
+		case r.URL.Path == "/broken/models":
+			w.Write([]byte(`<html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	list := func(apiKey, path string) ([]string, error) {
+		t.Helper()
+		p, err := NewGenericProvider(apiKey, "", server.URL+path, "test")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return p.ListModels(context.Background(), 0)
+	}
+
+	models, err := list("sk-test", "/v1/chat/completions")
+	if err != nil || !slices.Equal(models, []string{"gpt-4.1", "gpt-4o", "gpt-4o-mini"}) {
+		t.Errorf("expected the sorted models, got %v (%v)", models, err)
+	}
+
+	var statusErr *StatusError
+	if _, err := list("sk-test", "/none/chat/completions"); !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 status error, got %v", err)
+	}
+	if _, err := list("sk-wrong", "/v1/chat/completions"); !errors.As(err, &statusErr) || statusErr.Message != "invalid API key" {
+		t.Errorf("expected the API's error message, got %v", err)
+	}
+	if _, err := list("sk-test", "/broken/chat/completions"); err == nil {
+		t.Error("expected an error for a reply that is not JSON")
+	}
+}
@@ -0,0 +1,207 @@
+// Package feedback records how users edit generated commit messages before
+// committing, so that their corrections can guide future prompts. The store
+// lives in .git/gitc/feedback.json.
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/rezatg/gitc/internal/git"
+	"github.com/rezatg/gitc/internal/index"
+)
+
+// Feedback modes
+const (
+	ModeLearn = "learn" // record edits and add relevant corrections to prompts
+	ModeOff   = "off"   // neither record nor use corrections
+)
+
+// ValidMode reports whether mode is a supported feedback mode
+func ValidMode(mode string) bool {
+	return mode == ModeLearn || mode == ModeOff
+}
+
+// pendingTTL is how long a generated message waits to be committed
+const pendingTTL = 14 * 24 * time.Hour
+
+// searchDepth is the number of recent commits searched for pending messages
+const searchDepth = 50
+
+// Entry is a generated message and, once committed with changes, the final
+// message the user committed instead
+type Entry struct {
+	Fingerprint string    `json:"fingerprint"` // tree of the staged changes
+	Generated   string    `json:"generated"`
+	Final       string    `json:"final,omitempty"` // empty while pending
+	Terms       []string  `json:"terms"`           // query terms of the staged diff
+	Created     time.Time `json:"created"`
+}
+
+// Pending reports whether the message has not been committed yet
+func (e Entry) Pending() bool {
+	return e.Final == ""
+}
+
+// Store holds the recorded entries, oldest first
+type Store struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Path returns the location of the feedback store of the current repository
+func Path(ctx context.Context) (string, error) {
+	dir, err := git.GetGitDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gitc", "feedback.json"), nil
+}
+
+// Load reads the store from path; a missing store is empty
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Store{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	}
+
+	var s Store
+	if err := sonic.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse feedback %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the store to path, replacing the previous one atomically
+func (s *Store) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+
+	data, err := sonic.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feedback: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Record adds a generated message for the staged changes identified by
+// fingerprint, replacing a pending message for the same changes
+func (s *Store) Record(fingerprint, generated string, terms []string) {
+	s.Entries = s.remove(func(e Entry) bool { return e.Pending() && e.Fingerprint == fingerprint })
+	s.Entries = append(s.Entries, Entry{
+		Fingerprint: fingerprint,
+		Generated:   generated,
+		Terms:       terms,
+		Created:     time.Now().UTC(),
+	})
+}
+
+// Resolve looks up the pending messages in the recent commits by their tree.
+// Committed messages that differ from the generated ones are kept as
+// corrections, the others are dropped, as are pending messages that were
+// never committed. At most max corrections are kept. It reports whether the
+// store changed.
+func (s *Store) Resolve(ctx context.Context, max int) (bool, error) {
+	if !slices.ContainsFunc(s.Entries, Entry.Pending) {
+		return false, nil
+	}
+
+	trees, err := git.GetRecentCommitTrees(ctx, searchDepth)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	entries := make([]Entry, 0, len(s.Entries))
+	for _, e := range s.Entries {
+		if e.Pending() {
+			final, committed := trees[e.Fingerprint]
+			switch {
+			case committed && normalize(final) == normalize(e.Generated):
+				changed = true
+				continue // accepted as generated
+			case committed:
+				e.Final = final
+				changed = true
+			case time.Since(e.Created) > pendingTTL:
+				changed = true
+				continue
+			}
+		}
+		entries = append(entries, e)
+	}
+	s.Entries = entries
+
+	// Drop the oldest corrections beyond max
+	if excess := len(s.Corrections()) - max; max > 0 && excess > 0 {
+		s.Entries = s.remove(func(e Entry) bool {
+			if e.Pending() || excess == 0 {
+				return false
+			}
+			excess--
+			return true
+		})
+	}
+	return changed, nil
+}
+
+// Corrections returns the entries whose committed message differs from the
+// generated one, oldest first
+func (s *Store) Corrections() []Entry {
+	return s.remove(func(e Entry) bool { return e.Pending() })
+}
+
+// Relevant returns up to k corrections whose staged diffs best match the
+// query terms, best first
+func (s *Store) Relevant(terms []string, k int) []Entry {
+	corrections := s.Corrections()
+	ix := index.New()
+	for _, e := range corrections {
+		ix.AddTerms("", "", e.Final, e.Terms)
+	}
+
+	var relevant []Entry
+	for _, result := range ix.Search(terms, k, nil) {
+		relevant = append(relevant, corrections[result.Pos])
+	}
+	return relevant
+}
+
+// remove returns the entries for which drop is false
+func (s *Store) remove(drop func(Entry) bool) []Entry {
+	var kept []Entry
+	for _, e := range s.Entries {
+		if !drop(e) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// normalize strips whitespace, blank lines (git commit -m adds one between
+// every line), comment lines and Signed-off-by trailers, which do not count
+// as corrections
+func normalize(msg string) string {
+	var lines []string
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "Signed-off-by:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,96 @@
+package feedback
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndRelevant(t *testing.T) {
+	s := &Store{}
+	s.Record("tree1", "feat: add pool", []string{"pool"})
+	s.Record("tree1", "feat(db): add pool", []string{"pool"})
+	if len(s.Entries) != 1 || s.Entries[0].Generated != "feat(db): add pool" {
+		t.Fatalf("expected the pending message to be replaced, got %+v", s.Entries)
+	}
+
+	s.Entries[0].Final = "feat(db): support resizing the pool"
+	s.Record("tree2", "fix(api): handle token", []string{"token", "refresh"})
+	s.Entries[1].Final = "fix(api): refresh expired tokens"
+	s.Record("tree3", "docs: update readme", []string{"readme"})
+
+	if corrections := s.Corrections(); len(corrections) != 2 {
+		t.Errorf("expected 2 corrections, got %+v", corrections)
+	}
+	relevant := s.Relevant([]string{"token"}, 2)
+	if len(relevant) != 1 || relevant[0].Fingerprint != "tree2" {
+		t.Errorf("expected the token correction, got %+v", relevant)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Chdir(t.TempDir())
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("config", "user.name", "Test")
+	git("config", "user.email", "test@example.com")
+	git("config", "commit.gpgsign", "false")
+
+	// stage writes a file, stages it and returns the staged tree
+	stage := func(name string) string {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		return git("write-tree")
+	}
+
+	s := &Store{}
+	s.Record(stage("a.go"), "feat: add a", []string{"a"})
+	git("commit", "-q", "-m", "feat: add a\n\nSigned-off-by: Test <test@example.com>")
+	s.Record(stage("b.go"), "feat: add b", []string{"b"})
+	git("commit", "-q", "-m", "feat(b): add the b file")
+	s.Record("expired", "fix: stale", nil)
+	s.Entries[2].Created = time.Now().Add(-pendingTTL - time.Hour)
+	s.Record(stage("c.go"), "feat: add c", []string{"c"})
+
+	changed, err := s.Resolve(context.Background(), 10)
+	if err != nil || !changed {
+		t.Fatalf("expected the store to change, got %v (%v)", changed, err)
+	}
+
+	// The message committed unchanged and the expired one are dropped, the
+	// edited one is kept as a correction and the uncommitted one stays pending
+	if len(s.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", s.Entries)
+	}
+	if e := s.Entries[0]; e.Generated != "feat: add b" || e.Final != "feat(b): add the b file" {
+		t.Errorf("expected the edited message as a correction, got %+v", e)
+	}
+	if e := s.Entries[1]; e.Generated != "feat: add c" || !e.Pending() {
+		t.Errorf("expected the uncommitted message to stay pending, got %+v", e)
+	}
+
+	if changed, err := s.Resolve(context.Background(), 10); err != nil || changed {
+		t.Errorf("expected no further change, got %v (%v)", changed, err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	generated := "feat: add pool\n\nResize on demand.\nKeep idle ones."
+	committed := "feat: add pool\n\nResize on demand.\n\nKeep idle ones.\n\nSigned-off-by: A <a@example.com>\n# comment"
+	if normalize(generated) != normalize(committed) {
+		t.Errorf("expected formatting differences to be ignored: %q vs %q", normalize(generated), normalize(committed))
+	}
+}
@@ -1,10 +1,12 @@
 package git
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 )
@@ -13,17 +15,34 @@ import (
 type GitService interface {
 	GetDiff(ctx context.Context) (string, error)
 	StageAll(ctx context.Context) error
+	CurrentBranch(ctx context.Context) (string, error)
+	GetStagedFiles(ctx context.Context) ([]string, error)
+
+	// Operations used to split the staged changes into several commits
+	GetStagedPatch(ctx context.Context) (string, error)
+	WriteIndexTree(ctx context.Context) (string, error)
+	RestoreIndexTree(ctx context.Context, tree string) error
+	UnstageAll(ctx context.Context) error
+	ApplyCached(ctx context.Context, patch string) error
+	Commit(ctx context.Context, msg string, signOff bool) error
 }
 
 // gitServiceImpl implements GitService
 type gitServiceImpl struct {
 	excludeFiles []string
+	limits       DiffLimits
 }
 
 // NewGitService creates a new GitService
 func NewGitService(excludeFiles ...string) GitService {
+	return NewGitServiceWithLimits(DefaultDiffLimits(), excludeFiles...)
+}
+
+// NewGitServiceWithLimits creates a new GitService that enforces the given diff limits
+func NewGitServiceWithLimits(limits DiffLimits, excludeFiles ...string) GitService {
 	return &gitServiceImpl{
 		excludeFiles: append(defaultExcludeFiles, excludeFiles...),
+		limits:       limits,
 	}
 }
 
@@ -38,9 +57,26 @@ var defaultExcludeFiles = []string{
 	// "*.pdf", "*.zip", "*.gz",
 }
 
-// GetDiff retrieves the git diff for staged changes
+// GetDiff retrieves the git diff for staged changes, including a summary of
+// submodule pointer changes which the diff itself does not show.
 func (g *gitServiceImpl) GetDiff(ctx context.Context) (string, error) {
-	return GetDiffStaged(ctx, g.excludeFiles)
+	diff, err := GetDiffStagedWithLimits(ctx, g.excludeFiles, g.limits)
+	if err != nil && !errors.Is(err, ErrNoStagedChanges) {
+		return "", err
+	}
+
+	changes, subErr := GetSubmoduleChanges(ctx, g.excludeFiles)
+	if subErr != nil {
+		return "", subErr
+	} else if len(changes) == 0 {
+		return diff, err
+	}
+
+	summary := FormatSubmoduleChanges(changes)
+	if diff == "" {
+		return summary, nil
+	}
+	return diff + "\n\n" + summary, nil
 }
 
 // getGitRoot retrieves the root directory of the git repository
@@ -66,41 +102,198 @@ func getExcludeFileArgs(excludeFiles []string) []string {
 	return args
 }
 
-// processDiff applies cleanup to reduce unnecessary lines
-func processDiff(diff string) string {
-	var builder strings.Builder
-	lines := strings.Split(diff, "\n")
-	inHunk := false
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" {
-			continue
+// DiffLimits bounds how much of the staged diff is read before giving up.
+// A zero or negative value disables the corresponding check.
+type DiffLimits struct {
+	MaxBytes     int // total bytes of raw diff output
+	MaxLines     int // total lines of raw diff output
+	MaxFileBytes int // bytes of raw diff output for a single file
+	MaxFileLines int // lines of raw diff output for a single file
+}
+
+// DefaultDiffLimits returns limits generous enough for regular commits while
+// still catching accidentally staged data fixtures or generated files.
+func DefaultDiffLimits() DiffLimits {
+	return DiffLimits{
+		MaxBytes:     8 << 20,
+		MaxLines:     200000,
+		MaxFileBytes: 2 << 20,
+		MaxFileLines: 50000,
+	}
+}
+
+var (
+	// ErrDiffTooLarge is returned when the staged diff exceeds the configured limits
+	ErrDiffTooLarge = errors.New("staged diff too large")
+	// ErrNoStagedChanges is returned when there is nothing staged for commit
+	ErrNoStagedChanges = errors.New("no staged changes found")
+)
+
+// diffProcessor incrementally cleans up diff output line by line while
+// enforcing the configured size limits.
+type diffProcessor struct {
+	limits  DiffLimits
+	raw     bool // keep every line verbatim, e.g. for a patch to re-apply
+	builder strings.Builder
+	inHunk  bool
+
+	file       string
+	fileBytes  int
+	fileLines  int
+	totalBytes int
+	totalLines int
+}
+
+// maxLineSize returns the longest single line the processor can accept
+func (p *diffProcessor) maxLineSize() int {
+	size := 0
+	for _, limit := range []int{p.limits.MaxBytes, p.limits.MaxFileBytes} {
+		if limit > 0 && (size == 0 || limit < size) {
+			size = limit
+		}
+	}
+	if size == 0 {
+		return 64 << 20
+	}
+	return size
+}
+
+// checkLimits accounts for a raw line and reports whether any limit is exceeded
+func (p *diffProcessor) checkLimits(line string) error {
+	size := len(line) + 1
+	p.fileBytes += size
+	p.fileLines++
+	p.totalBytes += size
+	p.totalLines++
+
+	switch {
+	case p.limits.MaxFileBytes > 0 && p.fileBytes > p.limits.MaxFileBytes:
+		return fmt.Errorf("%w: %s exceeds the per-file limit of %d bytes; unstage it or add it to the exclude list",
+			ErrDiffTooLarge, p.fileName(), p.limits.MaxFileBytes)
+	case p.limits.MaxFileLines > 0 && p.fileLines > p.limits.MaxFileLines:
+		return fmt.Errorf("%w: %s exceeds the per-file limit of %d lines; unstage it or add it to the exclude list",
+			ErrDiffTooLarge, p.fileName(), p.limits.MaxFileLines)
+	case p.limits.MaxBytes > 0 && p.totalBytes > p.limits.MaxBytes:
+		return fmt.Errorf("%w: staged changes exceed the limit of %d bytes; commit them in smaller parts",
+			ErrDiffTooLarge, p.limits.MaxBytes)
+	case p.limits.MaxLines > 0 && p.totalLines > p.limits.MaxLines:
+		return fmt.Errorf("%w: staged changes exceed the limit of %d lines; commit them in smaller parts",
+			ErrDiffTooLarge, p.limits.MaxLines)
+	}
+	return nil
+}
+
+// fileName returns the file currently being processed for error messages
+func (p *diffProcessor) fileName() string {
+	if p.file == "" {
+		return "diff"
+	}
+	return p.file
+}
+
+// processLine applies cleanup to a single raw diff line
+func (p *diffProcessor) processLine(line string) error {
+	if strings.HasPrefix(line, "diff --git ") {
+		p.file = diffFileName(line)
+		p.fileBytes, p.fileLines = 0, 0
+	}
+	if err := p.checkLimits(line); err != nil {
+		return err
+	}
+	if p.raw {
+		p.builder.WriteString(line + "\n")
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(trimmed, "index "),
+		strings.HasPrefix(trimmed, "--- "),
+		strings.HasPrefix(trimmed, "+++ "):
+		return nil
+	case strings.HasPrefix(trimmed, "@@"):
+		p.inHunk = true
+		parts := strings.SplitN(trimmed, "@@", 3)
+		if len(parts) >= 3 {
+			p.builder.WriteString("@@" + strings.TrimSpace(parts[2]) + "\n")
 		}
+	case strings.HasPrefix(trimmed, " ") && p.inHunk:
+		return nil
+	default:
+		p.builder.WriteString(trimmed + "\n")
+	}
+	return nil
+}
+
+// process streams diff output from r through the processor
+func (p *diffProcessor) process(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	maxLine := p.maxLineSize()
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLine)), maxLine)
+	if p.raw {
+		scanner.Split(scanRawLines)
+	}
 
-		switch {
-		case strings.HasPrefix(trimmed, "index "),
-			strings.HasPrefix(trimmed, "--- "),
-			strings.HasPrefix(trimmed, "+++ "):
-			continue
-		case strings.HasPrefix(trimmed, "@@"):
-			inHunk = true
-			parts := strings.SplitN(trimmed, "@@", 3)
-			if len(parts) >= 3 {
-				builder.WriteString("@@" + strings.TrimSpace(parts[2]) + "\n")
-			}
-		case strings.HasPrefix(trimmed, " ") && inHunk:
-			continue
-		default:
-			builder.WriteString(trimmed + "\n")
+	for scanner.Scan() {
+		if err := p.processLine(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: %s contains a line longer than %d bytes; unstage it or add it to the exclude list",
+				ErrDiffTooLarge, p.fileName(), maxLine)
 		}
+		return fmt.Errorf("failed to read diff: %w", err)
+	}
+	return nil
+}
+
+// scanRawLines is bufio.ScanLines without dropping carriage returns, which
+// belong to the content of the changed lines
+func scanRawLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
 	}
+	return 0, nil, nil
+}
 
-	return strings.TrimSpace(builder.String())
+// result returns the cleaned up diff
+func (p *diffProcessor) result() string {
+	return strings.TrimSpace(p.builder.String())
+}
+
+// diffFileName extracts the destination path from a "diff --git a/x b/x" header
+func diffFileName(header string) string {
+	header = strings.TrimPrefix(header, "diff --git ")
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+3:]
+	}
+	return header
+}
+
+// processDiff applies cleanup to reduce unnecessary lines
+func processDiff(diff string) string {
+	p := &diffProcessor{}
+	_ = p.process(strings.NewReader(diff))
+	return p.result()
 }
 
 // GetDiffStaged retrieves the optimized git diff for staged changes with exclusions
 func GetDiffStaged(ctx context.Context, extraExcludeFiles []string) (string, error) {
+	return GetDiffStagedWithLimits(ctx, extraExcludeFiles, DefaultDiffLimits())
+}
+
+// GetDiffStagedWithLimits streams the staged diff from git, aborting as soon
+// as any of the given limits is exceeded instead of buffering the whole output.
+func GetDiffStagedWithLimits(ctx context.Context, extraExcludeFiles []string, limits DiffLimits) (string, error) {
 	rootPath, err := getGitRoot()
 	if err != nil {
 		return "", err
@@ -121,26 +314,17 @@ func GetDiffStaged(ctx context.Context, extraExcludeFiles []string) (string, err
 	}
 	args = append(args, getExcludeFileArgs(extraExcludeFiles)...)
 
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = rootPath
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-
-	if err := cmd.Run(); err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("git diff timed out: %w", ctx.Err())
-		}
-		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	p := &diffProcessor{limits: limits}
+	if err := streamGit(ctx, rootPath, args, p); err != nil {
+		return "", err
 	}
 
-	rawDiff := strings.TrimSpace(out.String())
-	if rawDiff == "" {
-		return "", errors.New("no staged changes found")
+	if p.totalLines == 0 {
+		return "", ErrNoStagedChanges
 	}
 
-	// Process diff to remove unnecessary lines
-	optimizedDiff := processDiff(rawDiff)
+	// Cleaned up diff with unnecessary lines removed
+	optimizedDiff := p.result()
 	if optimizedDiff == "" {
 		return "", errors.New("no meaningful staged changes after processing")
 	}
@@ -148,6 +332,41 @@ func GetDiffStaged(ctx context.Context, extraExcludeFiles []string) (string, err
 	return optimizedDiff, nil
 }
 
+// streamGit runs git in dir and feeds its output through the processor,
+// killing git as soon as the processor gives up
+func streamGit(ctx context.Context, dir string, args []string, p *diffProcessor) error {
+	// Cancelling kills git if we stop reading early
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	if err := p.process(stdout); err != nil {
+		cancel()
+		_ = cmd.Wait()
+		return err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("git %s timed out: %w", args[0], ctx.Err())
+		}
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
 // StageAll stages all changes in the working directory (equivalent to 'git add .').
 func (s *gitServiceImpl) StageAll(ctx context.Context) error {
 	cmd := exec.CommandContext(ctx, "git", "add", ".")
@@ -157,3 +376,44 @@ func (s *gitServiceImpl) StageAll(ctx context.Context) error {
 
 	return nil
 }
+
+// CurrentBranch returns the name of the checked out branch, or an empty
+// string when HEAD is detached.
+func (s *gitServiceImpl) CurrentBranch(ctx context.Context) (string, error) {
+	return GetCurrentBranch(ctx)
+}
+
+// GetStagedFiles lists the paths of staged files, skipping excluded files
+func (s *gitServiceImpl) GetStagedFiles(ctx context.Context) ([]string, error) {
+	return GetStagedFiles(ctx, s.excludeFiles)
+}
+
+// GetStagedPatch returns the full, re-applicable staged diff
+func (s *gitServiceImpl) GetStagedPatch(ctx context.Context) (string, error) {
+	return GetStagedPatch(ctx, s.limits)
+}
+
+// WriteIndexTree records the current index as a tree object
+func (s *gitServiceImpl) WriteIndexTree(ctx context.Context) (string, error) {
+	return WriteIndexTree(ctx)
+}
+
+// RestoreIndexTree replaces the index with a previously recorded tree
+func (s *gitServiceImpl) RestoreIndexTree(ctx context.Context, tree string) error {
+	return RestoreIndexTree(ctx, tree)
+}
+
+// UnstageAll removes all changes from the index
+func (s *gitServiceImpl) UnstageAll(ctx context.Context) error {
+	return UnstageAll(ctx)
+}
+
+// ApplyCached stages a patch without touching the working tree
+func (s *gitServiceImpl) ApplyCached(ctx context.Context, patch string) error {
+	return ApplyCached(ctx, patch)
+}
+
+// Commit records the staged changes with the given message
+func (s *gitServiceImpl) Commit(ctx context.Context, msg string, signOff bool) error {
+	return Commit(ctx, msg, signOff)
+}
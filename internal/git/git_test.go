@@ -3,6 +3,8 @@ package git
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -45,6 +47,151 @@ index 123456..abcdef 100644
 	}
 }
 
+func TestDiffProcessor_AbortsOnFileLimit(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("diff --git a/small.go b/small.go\n@@ -1 +1 @@\n+ok\n")
+	input.WriteString("diff --git a/data/fixture.json b/data/fixture.json\n@@ -0,0 +1,100 @@\n")
+	for i := 0; i < 100; i++ {
+		input.WriteString("+{\"id\": 1}\n")
+	}
+
+	p := &diffProcessor{limits: DiffLimits{MaxFileLines: 50}}
+	err := p.process(strings.NewReader(input.String()))
+	if !errors.Is(err, ErrDiffTooLarge) {
+		t.Fatalf("expected ErrDiffTooLarge, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "data/fixture.json") {
+		t.Errorf("expected error to name the offending file, got: %v", err)
+	}
+}
+
+func TestDiffProcessor_AbortsOnLongLine(t *testing.T) {
+	input := "diff --git a/min.js b/min.js\n+" + strings.Repeat("x", 4096) + "\n"
+
+	p := &diffProcessor{limits: DiffLimits{MaxFileBytes: 1024}}
+	if err := p.process(strings.NewReader(input)); !errors.Is(err, ErrDiffTooLarge) {
+		t.Fatalf("expected ErrDiffTooLarge, got %v", err)
+	}
+}
+
+// ------------------- ParsePatch -------------------
+
+func TestParsePatch_SplitsFilesAndHunks(t *testing.T) {
+	input := `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -2,3 +2,3 @@
+ 2
+-5
++five
+@@ -87,3 +87,3 @@
+ 89
+-90
++ninety
+diff --git a/bin.dat b/bin.dat
+index 3333333..4444444 100644
+GIT binary patch
+literal 3
+KcmZ?wbN~Pd
+
+literal 3
+KcmZ?wbN~Pe
+
+`
+	files := ParsePatch(input)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	if files[0].Path != "a.txt" || len(files[0].Hunks) != 2 {
+		t.Errorf("unexpected first file: %+v", files[0])
+	}
+	if !files[1].Binary || len(files[1].Hunks) != 0 {
+		t.Errorf("expected binary file without hunks: %+v", files[1])
+	}
+
+	patch := files[0].Patch([]int{1})
+	if strings.Contains(patch, "+five") || !strings.Contains(patch, "+ninety") || !strings.Contains(patch, "+++ b/a.txt") {
+		t.Errorf("unexpected partial patch:\n%s", patch)
+	}
+}
+
+// ------------------- submodules -------------------
+
+func TestParseRawSubmoduleLine(t *testing.T) {
+	oldSHA := strings.Repeat("a", 40)
+	newSHA := strings.Repeat("b", 40)
+
+	change, ok := parseRawSubmoduleLine(":160000 160000 " + oldSHA + " " + newSHA + " M\tlibs/sub")
+	if !ok {
+		t.Fatal("expected submodule entry to be parsed")
+	}
+	if change.Path != "libs/sub" || change.OldSHA != oldSHA || change.NewSHA != newSHA {
+		t.Errorf("unexpected change: %+v", change)
+	}
+
+	if _, ok := parseRawSubmoduleLine(":100644 100644 " + oldSHA + " " + newSHA + " M\tmain.go"); ok {
+		t.Error("expected regular file entry to be ignored")
+	}
+}
+
+func TestSubmoduleChange_Describe(t *testing.T) {
+	change := SubmoduleChange{
+		Path:     "libs/sub",
+		OldSHA:   strings.Repeat("a", 40),
+		NewSHA:   strings.Repeat("b", 40),
+		Subjects: []string{"c79a6dc add retries"},
+		Count:    3,
+	}
+
+	got := change.Describe()
+	for _, want := range []string{"submodule libs/sub advanced 3 commits", "- c79a6dc add retries", "... and 2 more"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected description to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestGetSubmoduleChanges(t *testing.T) {
+	upstream := t.TempDir()
+	runTestGit(t, "-C", upstream, "init", "-q", "-b", "main")
+	runTestGit(t, "-C", upstream, "-c", "user.name=Test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m", "chore: initial commit")
+
+	initTestRepo(t)
+	runTestGit(t, "-c", "protocol.file.allow=always", "submodule", "add", "-q", upstream, "libs/sub")
+	runTestGit(t, "commit", "-q", "-m", "chore: add submodule")
+
+	for _, msg := range []string{"feat: add retries", "fix: close idle connections"} {
+		runTestGit(t, "-C", "libs/sub", "-c", "user.name=Test", "-c", "user.email=test@example.com",
+			"commit", "-q", "--allow-empty", "-m", msg)
+	}
+	runTestGit(t, "add", "libs/sub")
+
+	changes, err := GetSubmoduleChanges(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetSubmoduleChanges returned error: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 submodule change, got %+v", changes)
+	}
+	change := changes[0]
+	if change.Path != "libs/sub" || change.Count != 2 || len(change.Subjects) != 2 {
+		t.Fatalf("unexpected change: %+v", change)
+	}
+	if !strings.HasSuffix(change.Subjects[0], "fix: close idle connections") {
+		t.Errorf("expected newest commit first, got %q", change.Subjects[0])
+	}
+
+	changes, err = GetSubmoduleChanges(context.Background(), []string{"libs/*"})
+	if err != nil {
+		t.Fatalf("GetSubmoduleChanges returned error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected excluded submodule to be skipped, got %+v", changes)
+	}
+}
+
 // ------------------- getGitRoot -------------------
 
 func TestGetGitRoot_NotInRepo(t *testing.T) {
@@ -88,3 +235,173 @@ func TestGetDiffStaged_NoChanges(t *testing.T) {
 		t.Errorf("unexpected diff output: %s", diff)
 	}
 }
+
+// ------------------- GetCommitMessages -------------------
+
+// initTestRepo creates a repository in a temporary directory and changes into it
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	runTestGit(t, "init", "-q", "-b", "main")
+	runTestGit(t, "config", "user.name", "Test")
+	runTestGit(t, "config", "user.email", "test@example.com")
+	runTestGit(t, "config", "commit.gpgsign", "false")
+	return dir
+}
+
+// runTestGit runs git in the current directory and returns its trimmed output
+func runTestGit(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGetCommitMessages(t *testing.T) {
+	initTestRepo(t)
+	runTestGit(t, "commit", "-q", "--allow-empty", "-m", "chore: initial commit")
+	runTestGit(t, "checkout", "-q", "-b", "topic")
+	runTestGit(t, "commit", "-q", "--allow-empty", "-m", "fix: close idle connections\n\nClose them after a minute.\n\nRefs: PAY-1")
+	runTestGit(t, "checkout", "-q", "main")
+	runTestGit(t, "commit", "-q", "--allow-empty", "-m", "docs: describe the pool")
+	runTestGit(t, "merge", "-q", "--no-ff", "-m", "Merge branch 'topic'", "topic")
+	head := runTestGit(t, "rev-parse", "HEAD^1")
+
+	commits, err := GetCommitMessages(context.Background(), "HEAD~2..HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected the two non-merge commits in the range, got %+v", commits)
+	}
+	if commits[0].SHA != head || commits[0].Message != "docs: describe the pool" {
+		t.Errorf("unexpected newest commit: %+v", commits[0])
+	}
+	if want := "fix: close idle connections\n\nClose them after a minute.\n\nRefs: PAY-1"; commits[1].Message != want {
+		t.Errorf("expected the full message %q, got %q", want, commits[1].Message)
+	}
+
+	if _, err := GetCommitMessages(context.Background(), "missing..HEAD"); err == nil {
+		t.Error("expected error for an unknown revision")
+	}
+}
+
+// ------------------- GetStagedPatch -------------------
+
+func TestGetStagedPatch(t *testing.T) {
+	initTestRepo(t)
+	if err := os.WriteFile("notes.txt", []byte("one\r\ntwo\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, "add", "notes.txt")
+	ctx := context.Background()
+
+	patch, err := GetStagedPatch(ctx, DiffLimits{MaxFileBytes: 1})
+	if err != nil {
+		t.Fatalf("expected only the total limits to apply, got %v", err)
+	}
+	if !strings.Contains(patch, "+one\r\n+two\r\n") {
+		t.Errorf("expected carriage returns to be kept, got %q", patch)
+	}
+
+	// The patch re-applies to an empty index as it was staged
+	if err := UnstageAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyCached(ctx, patch); err != nil {
+		t.Fatalf("failed to re-apply the patch: %v", err)
+	}
+	if staged := runTestGit(t, "diff", "--staged", "--name-only"); staged != "notes.txt" {
+		t.Errorf("expected notes.txt to be staged again, got %q", staged)
+	}
+
+	if _, err := GetStagedPatch(ctx, DiffLimits{MaxBytes: 64}); !errors.Is(err, ErrDiffTooLarge) {
+		t.Errorf("expected ErrDiffTooLarge, got %v", err)
+	}
+}
+
+// ------------------- VersionAtLeast -------------------
+
+func TestVersionAtLeast(t *testing.T) {
+	for version, want := range map[string]bool{
+		"2.43.0":           true,
+		"2.31":             true,
+		"2.31.0.windows.1": true,
+		"2.30.9":           false,
+		"1.99":             false,
+		"3.0":              true,
+	} {
+		if got := VersionAtLeast(version, MinVersion); got != want {
+			t.Errorf("VersionAtLeast(%q, %q) = %v, want %v", version, MinVersion, got, want)
+		}
+	}
+}
+
+// ------------------- benchmarks -------------------
+
+// syntheticDiff generates diff output on the fly so the benchmark input
+// itself never has to be held in memory.
+type syntheticDiff struct {
+	remaining int
+	line      int
+	pending   []byte
+}
+
+func (s *syntheticDiff) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.pending) == 0 {
+			if s.remaining <= 0 {
+				if n == 0 {
+					return 0, io.EOF
+				}
+				break
+			}
+			if s.line%1000 == 0 {
+				s.pending = []byte(fmt.Sprintf("diff --git a/f%d.txt b/f%d.txt\n@@ -0,0 +1,1000 @@\n", s.line, s.line))
+			} else {
+				s.pending = []byte(fmt.Sprintf("+line %d of generated fixture data\n", s.line))
+			}
+			s.line++
+			s.remaining -= len(s.pending)
+		}
+		c := copy(p[n:], s.pending)
+		s.pending = s.pending[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// BenchmarkDiffProcessor_Limited shows that memory stays bounded by the
+// configured limits regardless of how large the staged diff is.
+func BenchmarkDiffProcessor_Limited(b *testing.B) {
+	for _, size := range []int{4 << 20, 64 << 20, 256 << 20} {
+		b.Run(fmt.Sprintf("%dMiB", size>>20), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p := &diffProcessor{limits: DiffLimits{MaxBytes: 1 << 20}}
+				if err := p.process(&syntheticDiff{remaining: size}); !errors.Is(err, ErrDiffTooLarge) {
+					b.Fatalf("expected ErrDiffTooLarge, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDiffProcessor_Unlimited is the baseline where memory grows with the input.
+func BenchmarkDiffProcessor_Unlimited(b *testing.B) {
+	for _, size := range []int{4 << 20, 16 << 20} {
+		b.Run(fmt.Sprintf("%dMiB", size>>20), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p := &diffProcessor{}
+				if err := p.process(&syntheticDiff{remaining: size}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
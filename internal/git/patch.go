@@ -0,0 +1,183 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// FilePatch is the part of a unified diff that belongs to a single file
+type FilePatch struct {
+	Path   string
+	Header []string // "diff --git" line and extended headers up to the first hunk
+	Hunks  []Hunk
+	Binary bool
+}
+
+// Hunk is a single "@@" section of a file patch
+type Hunk struct {
+	Header string
+	Lines  []string
+}
+
+// ParsePatch splits a full unified diff into per-file patches and hunks
+func ParsePatch(raw string) []FilePatch {
+	var (
+		files []FilePatch
+		file  *FilePatch
+		hunk  *Hunk
+	)
+
+	lines := strings.Split(strings.TrimSuffix(raw, "\n"), "\n")
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, FilePatch{Path: diffFileName(line), Header: []string{line}})
+			file, hunk = &files[len(files)-1], nil
+		case file == nil:
+			continue
+		case strings.HasPrefix(line, "@@") && !file.Binary:
+			file.Hunks = append(file.Hunks, Hunk{Header: line})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+		case hunk != nil:
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			if strings.HasPrefix(line, "GIT binary patch") || strings.HasPrefix(line, "Binary files ") {
+				file.Binary = true
+			}
+			file.Header = append(file.Header, line)
+		}
+	}
+
+	return files
+}
+
+// String renders the complete file patch
+func (f FilePatch) String() string {
+	all := make([]int, len(f.Hunks))
+	for i := range all {
+		all[i] = i
+	}
+	return f.Patch(all)
+}
+
+// Patch renders the file patch restricted to the given hunk indexes
+func (f FilePatch) Patch(hunks []int) string {
+	var builder strings.Builder
+	for _, line := range f.Header {
+		builder.WriteString(line + "\n")
+	}
+
+	for _, i := range hunks {
+		if i < 0 || i >= len(f.Hunks) {
+			continue
+		}
+		builder.WriteString(f.Hunks[i].Header + "\n")
+		for _, line := range f.Hunks[i].Lines {
+			builder.WriteString(line + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// GetStagedPatch returns the full staged diff in a form that can be applied
+// again with `git apply --cached`. Nothing is excluded so that every staged
+// change survives being unstaged and re-applied, so only the total limits
+// apply; the diff is streamed and given up on as soon as it exceeds them.
+func GetStagedPatch(ctx context.Context, limits DiffLimits) (string, error) {
+	rootPath, err := getGitRoot()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{
+		"diff", "--staged", "--binary", "--full-index",
+		"--no-color", "--no-ext-diff", "--no-renames",
+	}
+	p := &diffProcessor{limits: DiffLimits{MaxBytes: limits.MaxBytes, MaxLines: limits.MaxLines}, raw: true}
+	if err := streamGit(ctx, rootPath, args, p); err != nil {
+		return "", err
+	}
+	return p.builder.String(), nil
+}
+
+// WriteIndexTree records the current index as a tree object so it can be restored later
+func WriteIndexTree(ctx context.Context) (string, error) {
+	out, err := runGit(ctx, "", "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to save index: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RestoreIndexTree replaces the index with a tree recorded by WriteIndexTree
+func RestoreIndexTree(ctx context.Context, tree string) error {
+	if _, err := runGit(ctx, "", "read-tree", tree); err != nil {
+		return fmt.Errorf("failed to restore index: %w", err)
+	}
+	return nil
+}
+
+// UnstageAll resets the index to HEAD, or empties it on an unborn branch
+func UnstageAll(ctx context.Context) error {
+	args := []string{"reset", "-q"}
+	if _, err := runGit(ctx, "", "rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		args = []string{"read-tree", "--empty"}
+	}
+
+	if _, err := runGit(ctx, "", args...); err != nil {
+		return fmt.Errorf("failed to unstage changes: %w", err)
+	}
+	return nil
+}
+
+// ApplyCached stages a patch without touching the working tree
+func ApplyCached(ctx context.Context, patch string) error {
+	if _, err := runGit(ctx, patch, "apply", "--cached", "--recount", "--whitespace=nowarn", "-"); err != nil {
+		return fmt.Errorf("failed to stage patch: %w", err)
+	}
+	return nil
+}
+
+// Commit records the staged changes with the given message, adding a
+// Signed-off-by footer if signOff is set
+func Commit(ctx context.Context, msg string, signOff bool) error {
+	args := []string{"commit", "-q", "-F", "-"}
+	if signOff {
+		args = append(args, "-s")
+	}
+	if _, err := runGit(ctx, msg, args...); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// runGit runs a git command in the repository root, feeding stdin if given,
+// and returns its standard output.
+func runGit(ctx context.Context, stdin string, args ...string) (string, error) {
+	rootPath, err := getGitRoot()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = rootPath
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return out.String(), nil
+}
@@ -1 +1,275 @@
 package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MinVersion is the oldest supported git version, the first with
+// rev-parse --path-format
+const MinVersion = "2.31"
+
+// GetVersion returns the version of the installed git, e.g. "2.43.0"
+func GetVersion(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git: %w", err)
+	}
+
+	// e.g. "git version 2.39.3 (Apple Git-146)"
+	fields := strings.Fields(string(out))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("unexpected git version %q", strings.TrimSpace(string(out)))
+	}
+	return fields[2], nil
+}
+
+// VersionAtLeast reports whether a git version is at least min, comparing
+// their numeric components
+func VersionAtLeast(version, min string) bool {
+	have, want := strings.Split(version, "."), strings.Split(min, ".")
+	for i := range want {
+		var h, w int
+		if i < len(have) {
+			fmt.Sscanf(have[i], "%d", &h)
+		}
+		fmt.Sscanf(want[i], "%d", &w)
+		if h != w {
+			return h > w
+		}
+	}
+	return true
+}
+
+// GetRepoRoot returns the top-level directory of the current repository
+func GetRepoRoot() (string, error) {
+	return getGitRoot()
+}
+
+// GetCurrentBranch returns the short name of the checked out branch. It works on
+// unborn branches and returns an empty string when HEAD is detached.
+func GetCurrentBranch(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "-q", "HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means HEAD is detached rather than a real failure
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetStagedFiles returns the paths, relative to the repository root, of all
+// staged files that are not excluded.
+func GetStagedFiles(ctx context.Context, excludeFiles []string) ([]string, error) {
+	rootPath, err := getGitRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"diff", "--staged", "--name-only", "-z", "--no-renames"}
+	args = append(args, getExcludeFileArgs(excludeFiles)...)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = rootPath
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var files []string
+	for _, file := range strings.Split(out.String(), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// CommitMessage is the full message of a commit
+type CommitMessage struct {
+	SHA     string
+	Message string
+}
+
+// GetCommitMessages returns the messages of the non-merge commits in a
+// revision range such as "origin/main..HEAD", newest first.
+func GetCommitMessages(ctx context.Context, revRange string) ([]CommitMessage, error) {
+	out, err := runGit(ctx, "", "log", "--no-merges", "--format=%H%x00%B%x1e", revRange, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits in %s: %w", revRange, err)
+	}
+
+	return parseCommitMessages(out), nil
+}
+
+// HistoryFilter selects the commits returned by GetRecentCommitMessages
+type HistoryFilter struct {
+	Max    int      // number of commits to search
+	Author string   // only commits by this author (name or email), if set
+	Paths  []string // only commits touching these paths, relative to the repository root
+}
+
+// GetRecentCommitMessages returns the messages of the most recent non-merge
+// commits reachable from HEAD that match the filter, newest first. A
+// repository without commits has none.
+func GetRecentCommitMessages(ctx context.Context, filter HistoryFilter) ([]CommitMessage, error) {
+	if head, _ := GetHeadSHA(ctx); head == "" {
+		return nil, nil // unborn branch
+	}
+
+	args := []string{"log", "--no-merges", "--format=%H%x00%B%x1e"}
+	if filter.Max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", filter.Max))
+	}
+	if filter.Author != "" {
+		args = append(args, "--fixed-strings", "--author="+filter.Author)
+	}
+	args = append(args, "HEAD", "--")
+	for _, path := range filter.Paths {
+		args = append(args, ":(top,literal)"+path)
+	}
+
+	out, err := runGit(ctx, "", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history: %w", err)
+	}
+	return parseCommitMessages(out), nil
+}
+
+// parseCommitMessages splits git log output in the %H%x00%B%x1e format
+func parseCommitMessages(out string) []CommitMessage {
+	var commits []CommitMessage
+	for _, record := range strings.Split(out, "\x1e") {
+		sha, message, found := strings.Cut(strings.TrimLeft(record, "\n"), "\x00")
+		if found {
+			commits = append(commits, CommitMessage{SHA: sha, Message: strings.TrimSpace(message)})
+		}
+	}
+	return commits
+}
+
+// GetUserEmail returns the configured user.email, or an empty string if unset
+func GetUserEmail(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "config", "user.email")
+	out, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means the key is not set
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read user.email: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetHookPath returns the path of a git hook, honoring core.hooksPath
+func GetHookPath(ctx context.Context, name string) (string, error) {
+	out, err := runGit(ctx, "", "rev-parse", "--path-format=absolute", "--git-path", "hooks/"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate %s hook: %w", name, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommitChange is a commit with its author and the paths it changed
+type CommitChange struct {
+	SHA     string
+	Author  string // author email
+	Message string
+	Paths   []string
+}
+
+// GetHeadSHA returns the commit HEAD points to, or an empty string on an
+// unborn branch
+func GetHeadSHA(ctx context.Context) (string, error) {
+	out, err := runGit(ctx, "", "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		return "", nil
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// IsAncestor reports whether commit ancestor is reachable from commit; a
+// commit that no longer exists is not an ancestor
+func IsAncestor(ctx context.Context, ancestor, commit string) bool {
+	_, err := runGit(ctx, "", "merge-base", "--is-ancestor", ancestor, commit)
+	return err == nil
+}
+
+// GetGitDir returns the absolute path of the repository's .git directory,
+// shared by all worktrees
+func GetGitDir(ctx context.Context) (string, error) {
+	out, err := runGit(ctx, "", "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GetCommitChanges returns the non-merge commits reachable from HEAD but not
+// from since (all of them when since is empty), newest first, at most max
+// when positive.
+func GetCommitChanges(ctx context.Context, since string, max int) ([]CommitChange, error) {
+	args := []string{"log", "--no-merges", "--name-only", "--format=%x1e%H%x00%ae%x00%B%x00"}
+	if max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", max))
+	}
+	args = append(args, "HEAD")
+	if since != "" {
+		args = append(args, "^"+since)
+	}
+
+	out, err := runGit(ctx, "", append(args, "--")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history: %w", err)
+	}
+
+	var commits []CommitChange
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(record, "\x00", 4)
+		if len(fields) < 4 {
+			continue
+		}
+
+		commit := CommitChange{SHA: fields[0], Author: fields[1], Message: strings.TrimSpace(fields[2])}
+		for _, path := range strings.Split(fields[3], "\n") {
+			if path = strings.TrimSpace(path); path != "" {
+				commit.Paths = append(commit.Paths, path)
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// GetRecentCommitTrees maps the trees of the last max commits reachable from
+// HEAD to their messages; the newest commit wins when several share a tree
+func GetRecentCommitTrees(ctx context.Context, max int) (map[string]string, error) {
+	if head, _ := GetHeadSHA(ctx); head == "" {
+		return nil, nil // unborn branch
+	}
+
+	out, err := runGit(ctx, "", "log", fmt.Sprintf("--max-count=%d", max), "--format=%T%x00%B%x1e", "HEAD", "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history: %w", err)
+	}
+
+	trees := make(map[string]string)
+	for _, record := range strings.Split(out, "\x1e") {
+		tree, message, found := strings.Cut(strings.TrimLeft(record, "\n"), "\x00")
+		if _, ok := trees[tree]; found && !ok {
+			trees[tree] = strings.TrimSpace(message)
+		}
+	}
+	return trees, nil
+}
@@ -0,0 +1,181 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// submoduleMode is the git file mode used for submodule and nested repository entries
+const submoduleMode = "160000"
+
+// maxSubmoduleSubjects limits how many commit subjects are listed per submodule
+const maxSubmoduleSubjects = 20
+
+// nullSHA is the object name git reports for a missing side of a change
+const nullSHA = "0000000000000000000000000000000000000000"
+
+// SubmoduleChange describes a staged change of a submodule pointer
+type SubmoduleChange struct {
+	Path     string
+	OldSHA   string
+	NewSHA   string
+	Subjects []string // commit subjects between the old and new pointer
+	Count    int      // number of commits between the old and new pointer
+	Rewound  bool     // the new pointer is behind the old one
+}
+
+// GetSubmoduleChanges lists staged submodule and nested repository pointer
+// changes along with the commits they introduce, skipping excluded paths.
+func GetSubmoduleChanges(ctx context.Context, excludeFiles []string) ([]SubmoduleChange, error) {
+	rootPath, err := getGitRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"diff", "--staged", "--raw", "--no-abbrev", "--no-renames", "--"}
+	args = append(args, getExcludeFileArgs(excludeFiles)...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = rootPath
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list staged submodules: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var changes []SubmoduleChange
+	for _, line := range strings.Split(out.String(), "\n") {
+		change, ok := parseRawSubmoduleLine(line)
+		if !ok {
+			continue
+		}
+		change.collectCommits(ctx, filepath.Join(rootPath, change.Path))
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// parseRawSubmoduleLine parses a `git diff --raw` line such as
+// ":160000 160000 <old> <new> M\tpath" and keeps only submodule entries.
+func parseRawSubmoduleLine(line string) (SubmoduleChange, bool) {
+	meta, path, found := strings.Cut(line, "\t")
+	if !found || !strings.HasPrefix(meta, ":") {
+		return SubmoduleChange{}, false
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(meta, ":"))
+	if len(fields) < 5 || (fields[0] != submoduleMode && fields[1] != submoduleMode) {
+		return SubmoduleChange{}, false
+	}
+
+	change := SubmoduleChange{Path: path}
+	if fields[0] == submoduleMode {
+		change.OldSHA = fields[2]
+	}
+	if fields[1] == submoduleMode {
+		change.NewSHA = fields[3]
+	}
+	return change, true
+}
+
+// collectCommits fills in the commits between the old and new pointer using the
+// submodule checkout at dir. Missing checkouts or objects are silently ignored.
+func (s *SubmoduleChange) collectCommits(ctx context.Context, dir string) {
+	if s.OldSHA == "" || s.NewSHA == "" || s.OldSHA == nullSHA || s.NewSHA == nullSHA {
+		return
+	}
+
+	subjects, err := submoduleLog(ctx, dir, s.OldSHA+".."+s.NewSHA)
+	if err == nil && len(subjects) == 0 {
+		if rewound, err := submoduleLog(ctx, dir, s.NewSHA+".."+s.OldSHA); err == nil && len(rewound) > 0 {
+			subjects, s.Rewound = rewound, true
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	s.Count = len(subjects)
+	if len(subjects) > maxSubmoduleSubjects {
+		subjects = subjects[:maxSubmoduleSubjects]
+	}
+	s.Subjects = subjects
+}
+
+// submoduleLog returns the one-line log of a revision range inside a submodule
+func submoduleLog(ctx context.Context, dir, revRange string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "log", "--oneline", "--no-decorate", "--no-color", revRange)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// Describe returns a human readable summary of the submodule change
+func (s SubmoduleChange) Describe() string {
+	var builder strings.Builder
+
+	switch {
+	case s.OldSHA == "" || s.OldSHA == nullSHA:
+		fmt.Fprintf(&builder, "submodule %s added at %s", s.Path, shortSHA(s.NewSHA))
+	case s.NewSHA == "" || s.NewSHA == nullSHA:
+		fmt.Fprintf(&builder, "submodule %s removed", s.Path)
+	case s.Count == 0:
+		fmt.Fprintf(&builder, "submodule %s updated from %s to %s", s.Path, shortSHA(s.OldSHA), shortSHA(s.NewSHA))
+	case s.Rewound:
+		fmt.Fprintf(&builder, "submodule %s rewound %d commit%s", s.Path, s.Count, plural(s.Count))
+	default:
+		fmt.Fprintf(&builder, "submodule %s advanced %d commit%s", s.Path, s.Count, plural(s.Count))
+	}
+
+	for _, subject := range s.Subjects {
+		builder.WriteString("\n  - " + subject)
+	}
+	if more := s.Count - len(s.Subjects); more > 0 {
+		fmt.Fprintf(&builder, "\n  - ... and %d more", more)
+	}
+
+	return builder.String()
+}
+
+// FormatSubmoduleChanges renders submodule changes as a section of the diff
+func FormatSubmoduleChanges(changes []SubmoduleChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	descriptions := make([]string, len(changes))
+	for i, change := range changes {
+		descriptions[i] = change.Describe()
+	}
+	return "Submodule changes:\n" + strings.Join(descriptions, "\n")
+}
+
+// shortSHA abbreviates an object name for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// plural returns the suffix for a count of commits
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
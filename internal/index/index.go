@@ -0,0 +1,224 @@
+// Package index maintains a local lexical index of past commits, stored
+// under .git/gitc/, and retrieves the commits most similar to a staged
+// change by BM25 over changed paths, identifiers and message text.
+package index
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/bytedance/sonic"
+	"github.com/rezatg/gitc/internal/git"
+)
+
+// version is bumped whenever the stored format or tokenization changes;
+// indexes of another version are rebuilt
+const version = 1
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// pathWeight is how often each path term is counted, so that commits touching
+// the same files rank above commits that merely mention similar words
+const pathWeight = 2
+
+// Document is an indexed commit
+type Document struct {
+	SHA     string         `json:"sha"`
+	Author  string         `json:"author"`
+	Message string         `json:"message"`
+	Terms   map[string]int `json:"terms"` // term frequencies
+	Length  int            `json:"length"`
+}
+
+// Result is a commit retrieved by Search
+type Result struct {
+	Document
+	Score float64
+	Pos   int // position of the document in Docs
+}
+
+// Index is the commit index, oldest commit first
+type Index struct {
+	Version int        `json:"version"`
+	Head    string     `json:"head"` // last indexed commit
+	Docs    []Document `json:"docs"`
+
+	df          map[string]int // number of documents containing each term
+	totalLength int
+}
+
+// New returns an empty index
+func New() *Index {
+	return &Index{Version: version, df: make(map[string]int)}
+}
+
+// Path returns the location of the index of the current repository
+func Path(ctx context.Context) (string, error) {
+	dir, err := git.GetGitDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gitc", "index.json"), nil
+}
+
+// Load reads an index from path. A missing index, or one written by another
+// version of gitc, yields an empty index.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	ix := New()
+	if err := sonic.Unmarshal(data, ix); err != nil || ix.Version != version {
+		return New(), nil // rebuilt from the history
+	}
+	for _, doc := range ix.Docs {
+		ix.count(doc, 1)
+	}
+	return ix, nil
+}
+
+// Save writes the index to path, replacing the previous one atomically
+func (ix *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	data, err := sonic.Marshal(ix)
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Update indexes the commits added to HEAD since the last update, keeping at
+// most max commits (all when max is not positive). When the history was
+// rewritten, e.g. by a rebase, the index is rebuilt. It reports whether the
+// index changed.
+func (ix *Index) Update(ctx context.Context, max int) (bool, error) {
+	head, err := git.GetHeadSHA(ctx)
+	if err != nil || head == "" || head == ix.Head {
+		return false, err
+	}
+
+	since := ix.Head
+	if since != "" && !git.IsAncestor(ctx, since, head) {
+		*ix = *New()
+		since = ""
+	}
+
+	commits, err := git.GetCommitChanges(ctx, since, max)
+	if err != nil {
+		return false, err
+	}
+	for _, commit := range slices.Backward(commits) {
+		ix.Add(commit)
+	}
+
+	ix.Trim(max)
+	ix.Head = head
+	return true, nil
+}
+
+// Add indexes a commit by its message and changed paths
+func (ix *Index) Add(commit git.CommitChange) {
+	terms := Tokenize(commit.Message)
+	for _, path := range commit.Paths {
+		for range pathWeight {
+			terms = append(terms, Tokenize(path)...)
+		}
+	}
+	ix.AddTerms(commit.SHA, commit.Author, commit.Message, terms)
+}
+
+// AddTerms indexes a document with the given terms; sha is empty for
+// documents that are not commits
+func (ix *Index) AddTerms(sha, author, message string, terms []string) {
+	doc := Document{SHA: sha, Author: author, Message: message, Terms: make(map[string]int), Length: len(terms)}
+	for _, term := range terms {
+		doc.Terms[term]++
+	}
+
+	ix.Docs = append(ix.Docs, doc)
+	ix.count(doc, 1)
+}
+
+// Trim drops the oldest commits so that at most max remain
+func (ix *Index) Trim(max int) {
+	if max <= 0 || len(ix.Docs) <= max {
+		return
+	}
+
+	for _, doc := range ix.Docs[:len(ix.Docs)-max] {
+		ix.count(doc, -1)
+	}
+	ix.Docs = slices.Clone(ix.Docs[len(ix.Docs)-max:])
+}
+
+// count adds (delta 1) or removes (delta -1) a document from the statistics
+func (ix *Index) count(doc Document, delta int) {
+	for term := range doc.Terms {
+		ix.df[term] += delta
+		if ix.df[term] <= 0 {
+			delete(ix.df, term)
+		}
+	}
+	ix.totalLength += delta * doc.Length
+}
+
+// Search returns the k commits that best match the query terms by BM25,
+// best first. Commits sharing no term with the query are left out, and
+// accept, if not nil, filters the candidates.
+func (ix *Index) Search(query []string, k int, accept func(Document) bool) []Result {
+	if len(ix.Docs) == 0 || k <= 0 {
+		return nil
+	}
+
+	n := float64(len(ix.Docs))
+	avgLength := float64(ix.totalLength) / n
+	idf := make(map[string]float64)
+	for _, term := range query {
+		if df := ix.df[term]; df > 0 {
+			idf[term] = math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+		}
+	}
+
+	// Documents are visited newest first so that newer commits win ties
+	var results []Result
+	for pos, doc := range slices.Backward(ix.Docs) {
+		score := 0.0
+		for term, weight := range idf {
+			tf := float64(doc.Terms[term])
+			if tf == 0 {
+				continue
+			}
+			score += weight * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(doc.Length)/avgLength))
+		}
+		if score > 0 && (accept == nil || accept(doc)) {
+			results = append(results, Result{Document: doc, Score: score, Pos: pos})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
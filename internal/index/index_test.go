@@ -0,0 +1,64 @@
+package index
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rezatg/gitc/internal/git"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Fix parseHTTPHeader_v2 in internal/git/diff.go")
+	want := []string{"parsehttpheader_v2", "parse", "http", "header", "internal", "git", "diff"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDiffTerms(t *testing.T) {
+	diff := "diff --git a/db/pool.go b/db/pool.go\n@@ func Open\n+func ResizePool(size int) {\n-\tPoolSize = 10\n"
+	terms := DiffTerms(diff)
+	for _, want := range []string{"pool", "resizepool", "resize", "size", "poolsize"} {
+		if !slices.Contains(terms, want) {
+			t.Errorf("expected %q in %q", want, terms)
+		}
+	}
+	if slices.Contains(terms, "open") {
+		t.Errorf("expected hunk headers to be skipped, got %q", terms)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ix := New()
+	ix.Add(git.CommitChange{SHA: "1", Author: "a@example.com", Message: "feat(db): add pool size setting", Paths: []string{"db/pool.go"}})
+	ix.Add(git.CommitChange{SHA: "2", Author: "b@example.com", Message: "feat(api): add token refresh", Paths: []string{"api/token.go"}})
+	ix.Add(git.CommitChange{SHA: "3", Author: "b@example.com", Message: "fix(db): close idle connections", Paths: []string{"db/pool.go"}})
+
+	results := ix.Search([]string{"pool", "resize"}, 5, nil)
+	if len(results) != 2 || results[0].SHA != "1" || results[1].SHA != "3" {
+		t.Errorf("expected the db commits, best match first, got %+v", results)
+	}
+	if results[0].Pos != 0 || results[1].Pos != 2 {
+		t.Errorf("expected the positions of the documents, got %+v", results)
+	}
+
+	results = ix.Search([]string{"pool"}, 5, func(doc Document) bool { return doc.Author == "a@example.com" })
+	if len(results) != 1 || results[0].SHA != "1" {
+		t.Errorf("expected the filtered commit only, got %+v", results)
+	}
+
+	ix.Trim(1)
+	if len(ix.Docs) != 1 || ix.df["token"] != 0 || ix.df["pool"] != 1 {
+		t.Errorf("expected only the newest commit after trimming, got %+v", ix.Docs)
+	}
+
+	path := filepath.Join(t.TempDir(), "gitc", "index.json")
+	if err := ix.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil || len(loaded.Docs) != 1 || loaded.df["pool"] != 1 {
+		t.Errorf("expected the saved index, got %+v, %v", loaded, err)
+	}
+}
@@ -0,0 +1,117 @@
+package index
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxQueryTerms bounds the number of distinct terms taken from a staged diff
+const maxQueryTerms = 100
+
+// wordPattern matches identifiers and words
+var wordPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_]*`)
+
+// stopWords are common English words and language keywords that carry no
+// information about what a change is about
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"this": true, "that": true, "are": true, "was": true, "not": true, "but": true,
+	"all": true, "when": true, "use": true, "add": true, "fix": true, "new": true,
+	"func": true, "return": true, "var": true, "const": true, "type": true, "nil": true,
+	"null": true, "true": true, "false": true, "else": true, "string": true, "int": true,
+	"err": true, "error": true, "import": true, "package": true, "def": true, "self": true,
+	"let": true, "class": true, "public": true, "private": true, "static": true, "void": true,
+}
+
+// Tokenize splits text into lower case terms. Identifiers are kept whole and
+// also split into their camelCase and snake_case parts; short words, numbers
+// and stop words are dropped.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, word := range wordPattern.FindAllString(text, -1) {
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			terms = appendTerm(terms, word)
+		}
+		for _, part := range parts {
+			terms = appendTerm(terms, part)
+		}
+	}
+	return terms
+}
+
+// appendTerm appends the lower case word unless it is too short or a stop word
+func appendTerm(terms []string, word string) []string {
+	word = strings.ToLower(word)
+	if len(word) < 3 || stopWords[word] {
+		return terms
+	}
+	return append(terms, word)
+}
+
+// splitIdentifier splits an identifier at underscores and case changes,
+// e.g. "parseHTTPHeader_v2" into "parse", "HTTP", "Header" and "v2"
+func splitIdentifier(word string) []string {
+	var parts []string
+	runes := []rune(word)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		boundary := i == len(runes) || runes[i] == '_'
+		if !boundary && unicode.IsUpper(runes[i]) {
+			// fooBar, or the last capital of an acronym in HTTPHeader
+			boundary = unicode.IsLower(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))
+		}
+		if !boundary {
+			continue
+		}
+
+		if part := strings.Trim(string(runes[start:i]), "_"); part != "" {
+			parts = append(parts, part)
+		}
+		start = i
+	}
+	return parts
+}
+
+// DiffTerms returns the query terms of a staged diff: the terms of the
+// changed paths, taken from the "diff --git" headers, and of the identifiers
+// on added and removed lines. The most frequent terms are kept, paths first.
+func DiffTerms(diff string) []string {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			path := line
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				path = line[i+3:]
+			}
+			for _, term := range Tokenize(path) {
+				counts[term] += maxQueryTerms // always kept
+			}
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			// file names, already taken from the header
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+			for _, term := range Tokenize(line[1:]) {
+				counts[term]++
+			}
+		}
+	}
+
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if counts[terms[i]] != counts[terms[j]] {
+			return counts[terms[i]] > counts[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > maxQueryTerms {
+		terms = terms[:maxQueryTerms]
+	}
+	return terms
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// APIKeySources are the settings the API key can be read from; at most one
+// of them may be set
+var APIKeySources = []string{"api_key", "api_key_cmd", "api_key_file", "api_key_env"}
+
+// keyCommands caches the output of API key commands, so that a command such
+// as a password manager prompt runs at most once per process
+var keyCommands = struct {
+	sync.Mutex
+	keys map[string]string
+}{keys: make(map[string]string)}
+
+// ResolveAPIKey returns the API key from the configured source: the api_key
+// setting itself, the output of api_key_cmd, the contents of api_key_file or
+// the environment variable named by api_key_env. It returns an empty key
+// when no source is set.
+func (p *Profile) ResolveAPIKey() (string, error) {
+	switch {
+	case p.APIKey != "":
+		return p.APIKey, nil
+	case p.APIKeyCmd != "":
+		return runKeyCommand(p.APIKeyCmd)
+	case p.APIKeyFile != "":
+		return readKeyFile(p.APIKeyFile)
+	case p.APIKeyEnv != "":
+		key := strings.TrimSpace(os.Getenv(p.APIKeyEnv))
+		if key == "" {
+			return "", fmt.Errorf("api_key_env: $%s is not set", p.APIKeyEnv)
+		}
+		return key, nil
+	}
+	return "", nil
+}
+
+// KeySource returns the name of the setting the API key is read from, or an
+// empty string if there is none. It fails if more than one is set.
+func (p *Profile) KeySource() (string, error) {
+	var sources []string
+	for i, value := range []string{p.APIKey, p.APIKeyCmd, p.APIKeyFile, p.APIKeyEnv} {
+		if value != "" {
+			sources = append(sources, APIKeySources[i])
+		}
+	}
+	if len(sources) > 1 {
+		return "", fmt.Errorf("only one of %s may be set", strings.Join(sources, ", "))
+	}
+	if len(sources) == 0 {
+		return "", nil
+	}
+	return sources[0], nil
+}
+
+// runKeyCommand runs an API key command in the shell and returns its output
+func runKeyCommand(command string) (string, error) {
+	keyCommands.Lock()
+	defer keyCommands.Unlock()
+	if key, ok := keyCommands.keys[command]; ok {
+		return key, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin, cmd.Stderr = os.Stdin, os.Stderr // e.g. a passphrase prompt
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("api_key_cmd %q failed: %w", command, err)
+	}
+
+	// Password managers print the secret on the first line
+	key, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("api_key_cmd %q printed no key", command)
+	}
+	keyCommands.keys[command] = key
+	return key, nil
+}
+
+// readKeyFile reads an API key file; a leading ~ is the home directory
+func readKeyFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("api_key_file: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("api_key_file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("api_key_file: %s is empty", path)
+	}
+	return key, nil
+}
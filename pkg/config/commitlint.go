@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// commitlintFiles are the commitlint configuration files looked up at the
+// repository root, in order. JavaScript configurations cannot be read.
+var commitlintFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+	"package.json",
+}
+
+// commitlintLevelError is the commitlint rule level that fails the check;
+// disabled (0) and warning (1) rules are not enforced
+const commitlintLevelError = 2
+
+// commitlintShared holds the rules of the shared configurations most often
+// referenced via "extends", as they cannot be resolved from node_modules
+var commitlintShared = map[string]map[string]any{
+	"@commitlint/config-conventional": {
+		"type-enum": []any{2, "always", []any{
+			"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+		}},
+		"header-max-length":    []any{2, "always", 100},
+		"body-max-line-length": []any{2, "always", 100},
+		"subject-case":         []any{2, "never", []any{"sentence-case", "start-case", "pascal-case", "upper-case"}},
+	},
+	"@commitlint/config-angular": {
+		"type-enum": []any{2, "always", []any{
+			"build", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+		}},
+		"header-max-length":    []any{2, "always", 72},
+		"body-max-line-length": []any{2, "always", 100},
+		"subject-case":         []any{2, "never", []any{"sentence-case", "start-case", "pascal-case", "upper-case"}},
+	},
+}
+
+// LoadCommitlint looks for a commitlint configuration in dir, usually the
+// repository root, and translates its common rules (type-enum, scope-enum,
+// scope-empty, header-max-length, subject-case and body-max-line-length) into
+// a convention. It returns the path of the configuration used, or a nil
+// convention if there is none.
+func LoadCommitlint(dir string) (*Convention, string, error) {
+	for _, name := range commitlintFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, path, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		cfg, err := decodeCommitlint(name, data)
+		if err != nil {
+			return nil, path, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if cfg == nil {
+			continue // package.json without a commitlint key
+		}
+
+		conv, err := commitlintConvention(cfg)
+		if err != nil {
+			return nil, path, fmt.Errorf("unsupported rules in %s: %w", name, err)
+		}
+		return conv, path, nil
+	}
+
+	return nil, "", nil
+}
+
+// decodeCommitlint decodes a commitlint configuration file
+func decodeCommitlint(name string, data []byte) (map[string]any, error) {
+	var value any
+	var err error
+	switch {
+	case name == "package.json":
+		var pkg map[string]any
+		if err := sonic.Unmarshal(data, &pkg); err != nil {
+			return nil, err
+		}
+		value = pkg["commitlint"]
+		if value == nil {
+			return nil, nil
+		}
+	case strings.HasSuffix(name, ".json") || strings.HasPrefix(strings.TrimSpace(string(data)), "{"):
+		err = sonic.Unmarshal(data, &value)
+	default:
+		value, err = decodeYAML(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %T", value)
+	}
+	return cfg, nil
+}
+
+// commitlintConvention translates the rules of a commitlint configuration.
+// Rules of shared configurations are applied before the local rules.
+func commitlintConvention(cfg map[string]any) (*Convention, error) {
+	conv := &Convention{}
+
+	var extends []string
+	switch value := cfg["extends"].(type) {
+	case string:
+		extends = []string{value}
+	case []any:
+		extends = stringList(value)
+	}
+	for _, name := range extends {
+		if rules, ok := commitlintShared[name]; ok {
+			applyCommitlintRules(conv, rules)
+		}
+	}
+
+	if rules, ok := cfg["rules"].(map[string]any); ok {
+		applyCommitlintRules(conv, rules)
+	}
+
+	return conv, conv.Validate()
+}
+
+// applyCommitlintRules applies the supported rules to the convention. Rules
+// that are disabled or only warn reset the corresponding setting.
+func applyCommitlintRules(conv *Convention, rules map[string]any) {
+	for name, value := range rules {
+		level, condition, arg, ok := commitlintRule(value)
+		if !ok {
+			continue
+		}
+		enforced := level == commitlintLevelError
+		always := condition != "never"
+
+		switch name {
+		case "type-enum":
+			conv.Types = nil
+			if enforced && always {
+				conv.Types = stringList(arg)
+			}
+		case "scope-enum":
+			conv.Scopes = nil
+			if enforced && always {
+				conv.Scopes = stringList(arg)
+			}
+		case "scope-empty":
+			conv.RequireScope = enforced && !always
+		case "header-max-length":
+			conv.MaxHeaderLength = 0
+			if enforced && always {
+				conv.MaxHeaderLength = intValue(arg)
+			}
+		case "body-max-line-length":
+			conv.MaxBodyLineLength = 0
+			if enforced && always {
+				conv.MaxBodyLineLength = intValue(arg)
+			}
+		case "subject-case":
+			conv.SubjectCase = ""
+			if enforced {
+				conv.SubjectCase = commitlintSubjectCase(always, arg)
+			}
+		}
+	}
+}
+
+// commitlintRule splits a rule of the form [level, "always"|"never", value]
+func commitlintRule(value any) (int, string, any, bool) {
+	parts, ok := value.([]any)
+	if !ok || len(parts) == 0 {
+		return 0, "", nil, false
+	}
+
+	level := intValue(parts[0])
+	condition := "always"
+	if len(parts) > 1 {
+		condition, _ = parts[1].(string)
+	}
+	var arg any
+	if len(parts) > 2 {
+		arg = parts[2]
+	}
+	return level, condition, arg, true
+}
+
+// commitlintSubjectCase maps the commitlint subject-case rule onto the
+// lower or sentence subject case
+func commitlintSubjectCase(always bool, arg any) string {
+	cases := stringList(arg)
+	if s, ok := arg.(string); ok {
+		cases = []string{s}
+	}
+
+	lower := slices.Contains(cases, "lower-case")
+	sentence := slices.Contains(cases, "sentence-case")
+	switch {
+	case always && lower && !sentence:
+		return SubjectCaseLower
+	case always && sentence && !lower:
+		return SubjectCaseSentence
+	case !always && sentence && !lower:
+		return SubjectCaseLower // e.g. never sentence-case, start-case, ...
+	case !always && lower && !sentence:
+		return SubjectCaseSentence
+	}
+	return ""
+}
+
+// stringList converts a decoded list to strings, skipping other values
+func stringList(value any) []string {
+	items, _ := value.([]any)
+	var list []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// intValue converts a decoded number to an int
+func intValue(value any) int {
+	switch n := value.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
@@ -2,108 +2,291 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
-
-	"github.com/bytedance/sonic"
+	"reflect"
+	"slices"
 )
 
-// Config holds the main configuration structure for the gitc CLI tool
+// Config holds the main configuration structure for the gitc CLI tool. Its
+// top-level profile settings form the implicit default profile.
 type Config struct {
+	// Version is the layout version of the config file, see CurrentVersion
+	Version int `json:"version,omitempty"`
+
+	Profile
+
+	MaxLength    int    `json:"max_length"`
+	Language     string `json:"language"`
+	Timeout      int    `json:"timeout"`
+	CommitType   string `json:"commit_type"`
+	UseGitmoji   bool   `json:"use_gitmoji"`
+	MaxRedirects int    `json:"max_redirects"`
+
+	// Profiles are named sets of profile settings applied over the default
+	// profile; DefaultProfile names the one used when none is selected
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+	DefaultProfile string             `json:"default_profile,omitempty"`
+
+	// ExcludeFiles are left out of the diff in addition to the defaults
+	ExcludeFiles []string `json:"exclude_files"`
+
+	Diff   DiffConfig   `json:"diff"`
+	Ticket TicketConfig `json:"ticket"`
+	Scope  ScopeConfig  `json:"scope"`
+
+	TypeHint   TypeHintConfig   `json:"type_hint"`
+	Validation ValidationConfig `json:"validation"`
+	Examples   ExamplesConfig   `json:"examples"`
+	Feedback   FeedbackConfig   `json:"feedback"`
+}
+
+// Profile holds the settings that change together when switching between
+// AI providers, e.g. a work gateway, a personal key and a local model
+type Profile struct {
 	Provider         string `json:"provider"`
-	APIKey           string `json:"api_key"`
 	Model            string `json:"model"`
 	URL              string `json:"url"`
-	MaxLength        int    `json:"max_length"`
+	APIKey           string `json:"api_key"`
+	APIKeyCmd        string `json:"api_key_cmd,omitempty"`  // command printing the key
+	APIKeyFile       string `json:"api_key_file,omitempty"` // file holding the key
+	APIKeyEnv        string `json:"api_key_env,omitempty"`  // environment variable holding the key
 	Proxy            string `json:"proxy"`
-	Language         string `json:"language"`
-	Timeout          int    `json:"timeout"`
-	CommitType       string `json:"commit_type"`
 	CustomConvention string `json:"custom_convention"`
-	UseGitmoji       bool   `json:"use_gitmoji"`
-	MaxRedirects     int    `json:"max_redirects"`
+	Preset           string `json:"preset"`
+}
+
+// DiffConfig bounds the size of the staged diff read from git. A zero limit
+// takes the default and -1 disables the limit.
+type DiffConfig struct {
+	MaxBytes     int `json:"max_bytes"`
+	MaxLines     int `json:"max_lines"`
+	MaxFileBytes int `json:"max_file_bytes"`
+	MaxFileLines int `json:"max_file_lines"`
+}
+
+// TicketConfig controls how ticket IDs are extracted from the branch name
+// and where they are placed in the commit message
+type TicketConfig struct {
+	Pattern   string `json:"pattern"`
+	Placement string `json:"placement"` // prefix, scope or footer
+}
+
+// ScopeConfig controls how the Conventional Commits scope is inferred from
+// the staged file paths
+type ScopeConfig struct {
+	Mode  string      `json:"mode"` // off, suggest or require
+	Root  string      `json:"root"` // scope defaults to the first directory under root
+	Rules []ScopeRule `json:"rules"`
+}
+
+// TypeHintConfig controls how the commit type is inferred from the staged
+// file paths. Rules are checked before the built-in defaults.
+type TypeHintConfig struct {
+	Mode  string     `json:"mode"` // off, hint or enforce
+	Rules []TypeRule `json:"rules"`
+}
+
+// TypeRule maps files matching a glob pattern to a commit type
+type TypeRule struct {
+	Pattern string `json:"pattern"`
+	Type    string `json:"type"`
+}
+
+// ValidationConfig controls how generated messages are checked against the
+// Conventional Commits rules and repaired when they fail
+type ValidationConfig struct {
+	Mode              string   `json:"mode"` // repair, fix or off
+	MaxAttempts       int      `json:"max_attempts"`
+	MaxHeaderLength   int      `json:"max_header_length"`
+	MaxBodyLineLength int      `json:"max_body_line_length"`
+	Types             []string `json:"types"`
+}
+
+// ExamplesConfig controls where the example messages in the prompt come from
+// and how commits are sampled from the repository history
+type ExamplesConfig struct {
+	Source     string `json:"source"` // history, similar or preset
+	Count      int    `json:"count"`
+	Depth      int    `json:"depth"`       // number of recent commits searched or indexed
+	SameAuthor bool   `json:"same_author"` // only commits by the configured user.email
+	SamePaths  bool   `json:"same_paths"`  // only commits touching the staged files
+}
+
+// FeedbackConfig controls how edits made to generated messages before
+// committing are recorded and passed to future prompts
+type FeedbackConfig struct {
+	Mode           string `json:"mode"`            // learn or off
+	Count          int    `json:"count"`           // corrections added to a prompt
+	MaxCorrections int    `json:"max_corrections"` // corrections kept per repository
+}
+
+// ScopeRule maps files matching a glob pattern to a scope
+type ScopeRule struct {
+	Pattern string `json:"pattern"`
+	Scope   string `json:"scope"`
 }
 
 // DefaultConfig returns a default config with fallback values
 func DefaultConfig() *Config {
 	return &Config{
-		Provider:         "openai",
-		APIKey:           os.Getenv("AI_API_KEY"),
-		Model:            "gpt-4o-mini",
-		URL:              "https://api.openai.com/v1/chat/completions",
-		MaxLength:        250,
-		Proxy:            "",
-		Language:         "en",
-		Timeout:          10,
-		CommitType:       "",
-		CustomConvention: "",
-		UseGitmoji:       false,
-		MaxRedirects:     5,
+		Profile: Profile{
+			Provider:         "openai",
+			Model:            "gpt-4o-mini",
+			URL:              "https://api.openai.com/v1/chat/completions",
+			APIKey:           "",
+			Proxy:            "",
+			CustomConvention: "",
+			Preset:           "conventional",
+		},
+		MaxLength:    250,
+		Language:     "en",
+		Timeout:      10,
+		CommitType:   "",
+		UseGitmoji:   false,
+		MaxRedirects: 5,
+		Diff: DiffConfig{
+			MaxBytes:     8 << 20,
+			MaxLines:     200000,
+			MaxFileBytes: 2 << 20,
+			MaxFileLines: 50000,
+		},
+		Ticket: TicketConfig{
+			Pattern:   `[A-Z][A-Z0-9]+-[0-9]+`,
+			Placement: "footer",
+		},
+		Scope: ScopeConfig{
+			Mode: "suggest",
+		},
+		TypeHint: TypeHintConfig{
+			Mode: "hint",
+		},
+		Validation: ValidationConfig{
+			Mode:              "repair",
+			MaxAttempts:       2,
+			MaxHeaderLength:   72,
+			MaxBodyLineLength: 100,
+		},
+		Examples: ExamplesConfig{
+			Source: "history",
+			Count:  3,
+			Depth:  200,
+		},
+		Feedback: FeedbackConfig{
+			Mode:           "learn",
+			Count:          2,
+			MaxCorrections: 100,
+		},
 	}
 }
 
-// configPath points to ~/.gitc/config.json by default (hidden config file)
-var configPath = filepath.Join(userHomeDir(), ".gitc", "config.json")
+// DefaultConfigFiles are looked up in ~/.gitc, in order, when no config
+// path is set; the first one is created when none exists
+var DefaultConfigFiles = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// configPath is the config file set with SetConfigPath, if any
+var configPath string
 
 // SetConfigPath updates the configuration file path
 func SetConfigPath(path string) {
 	configPath = path
 }
 
+// Path returns the absolute path of the config file. Its format is given by
+// its extension.
+func Path() (string, error) {
+	if configPath == "" {
+		for _, name := range DefaultConfigFiles {
+			path := filepath.Join(Dir(), name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+		return filepath.Join(Dir(), DefaultConfigFiles[0]), nil
+	}
+
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	return absPath, nil
+}
+
 // Load loads the configuration from file or creates a default one if it doesn't exist
 func Load() (*Config, error) {
-	absPath, err := filepath.Abs(configPath)
+	_, values, _, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := decodeConfig(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	ApplyDefaults(cfg)
+	return cfg, nil
+}
+
+// Parse decodes the content of a config file in the given format and
+// applies the defaults
+func Parse(data []byte, format Format) (*Config, error) {
+	values, err := format.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	cfg, err := decodeConfig(values)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	ApplyDefaults(cfg)
+	return cfg, nil
+}
+
+// readConfig returns the path and settings of the config file, creating a
+// default one if it doesn't exist and upgrading one of an older layout
+func readConfig() (string, map[string]any, *Migration, error) {
+	absPath, err := Path()
+	if err != nil {
+		return "", nil, nil, err
 	}
 
 	data, err := os.ReadFile(absPath)
 	if os.IsNotExist(err) {
-		cfg := DefaultConfig()
-		if err := Save(cfg); err != nil {
-			return nil, fmt.Errorf("failed to create default config: %w", err)
+		if err := Save(DefaultConfig()); err != nil {
+			return "", nil, nil, fmt.Errorf("failed to create default config: %w", err)
 		}
-		return cfg, nil
-	} else if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		data, err = os.ReadFile(absPath)
+	}
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var cfg Config
-	if err := sonic.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	values, err := FormatOf(absPath).decode(data)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	migration, err := upgradeConfig(absPath, data, values)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return absPath, values, migration, nil
+}
 
-	// Apply defaults for unset fields
+// ApplyDefaults sets the default values of unset fields
+func ApplyDefaults(cfg *Config) {
 	defaults := DefaultConfig()
 	if cfg.Provider == "" {
 		cfg.Provider = defaults.Provider
 	}
-	if cfg.APIKey == "" {
-		cfg.APIKey = defaults.APIKey
-	}
+	model, url := providerDefaults(cfg.Provider)
 	if cfg.Model == "" {
-		switch cfg.Provider {
-		case "openai":
-			cfg.Model = "gpt-4o-mini"
-		case "grok":
-			cfg.Model = "grok-3"
-		case "deepseek":
-			cfg.Model = "deepseek-rag"
-		default:
-			cfg.Model = defaults.Model
-		}
+		cfg.Model = model
 	}
 	if cfg.URL == "" {
-		switch cfg.Provider {
-		case "openai":
-			cfg.URL = "https://api.openai.com/v1/chat/completions"
-		case "grok":
-			cfg.URL = "https://api.x.ai/v1/chat/completions"
-		case "deepseek":
-			cfg.URL = "https://api.deepseek.com/v1/chat/completions"
-		default:
-			cfg.URL = defaults.URL
-		}
+		cfg.URL = url
 	}
 	if cfg.MaxLength == 0 {
 		cfg.MaxLength = defaults.MaxLength
@@ -117,15 +300,80 @@ func Load() (*Config, error) {
 	if cfg.MaxRedirects == 0 {
 		cfg.MaxRedirects = defaults.MaxRedirects
 	}
+	if cfg.Preset == "" {
+		cfg.Preset = defaults.Preset
+	}
+	if cfg.Diff.MaxBytes == 0 {
+		cfg.Diff.MaxBytes = defaults.Diff.MaxBytes
+	}
+	if cfg.Diff.MaxLines == 0 {
+		cfg.Diff.MaxLines = defaults.Diff.MaxLines
+	}
+	if cfg.Diff.MaxFileBytes == 0 {
+		cfg.Diff.MaxFileBytes = defaults.Diff.MaxFileBytes
+	}
+	if cfg.Diff.MaxFileLines == 0 {
+		cfg.Diff.MaxFileLines = defaults.Diff.MaxFileLines
+	}
+	if cfg.Ticket.Pattern == "" {
+		cfg.Ticket.Pattern = defaults.Ticket.Pattern
+	}
+	if cfg.Ticket.Placement == "" {
+		cfg.Ticket.Placement = defaults.Ticket.Placement
+	}
+	if cfg.Scope.Mode == "" {
+		cfg.Scope.Mode = defaults.Scope.Mode
+	}
+	if cfg.TypeHint.Mode == "" {
+		cfg.TypeHint.Mode = defaults.TypeHint.Mode
+	}
+	if cfg.Validation.Mode == "" {
+		cfg.Validation.Mode = defaults.Validation.Mode
+	}
+	if cfg.Validation.MaxAttempts == 0 {
+		cfg.Validation.MaxAttempts = defaults.Validation.MaxAttempts
+	}
+	if cfg.Validation.MaxHeaderLength == 0 {
+		cfg.Validation.MaxHeaderLength = defaults.Validation.MaxHeaderLength
+	}
+	if cfg.Validation.MaxBodyLineLength == 0 {
+		cfg.Validation.MaxBodyLineLength = defaults.Validation.MaxBodyLineLength
+	}
+	if cfg.Examples.Source == "" {
+		cfg.Examples.Source = defaults.Examples.Source
+	}
+	if cfg.Examples.Count == 0 {
+		cfg.Examples.Count = defaults.Examples.Count
+	}
+	if cfg.Examples.Depth == 0 {
+		cfg.Examples.Depth = defaults.Examples.Depth
+	}
+	if cfg.Feedback.Mode == "" {
+		cfg.Feedback.Mode = defaults.Feedback.Mode
+	}
+	if cfg.Feedback.Count == 0 {
+		cfg.Feedback.Count = defaults.Feedback.Count
+	}
+	if cfg.Feedback.MaxCorrections == 0 {
+		cfg.Feedback.MaxCorrections = defaults.Feedback.MaxCorrections
+	}
+}
 
-	return &cfg, nil
+// providerDefaults returns the default model and URL of a provider, or those
+// of the default provider for one that is not supported
+func providerDefaults(provider string) (string, string) {
+	if p, ok := LookupProvider(provider); ok {
+		return p.Model, p.URL
+	}
+	defaults := DefaultConfig()
+	return defaults.Model, defaults.URL
 }
 
-// Save saves the configuration to file
+// Save saves the configuration to file, in the format of its extension
 func Save(cfg *Config) error {
-	absPath, err := filepath.Abs(configPath)
+	absPath, err := Path()
 	if err != nil {
-		return fmt.Errorf("failed to resolve config path: %w", err)
+		return err
 	}
 
 	dir := filepath.Dir(absPath)
@@ -133,7 +381,7 @@ func Save(cfg *Config) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := sonic.MarshalIndent(cfg, "", "  ")
+	data, err := Encode(cfg, FormatOf(absPath))
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	} else if err := os.WriteFile(absPath, data, 0600); err != nil {
@@ -143,11 +391,105 @@ func Save(cfg *Config) error {
 	return nil
 }
 
+// Update applies fn to the config file and saves the settings it changed.
+// fn is given the config as written, without defaults, so that settings
+// left unset keep following their defaults, e.g. the model of the provider.
+func Update(fn func(cfg *Config) error) error {
+	absPath, values, _, err := readConfig()
+	if err != nil {
+		return err
+	}
+	cfg, err := decodeConfig(values)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	before, err := decodeValues(cfg)
+	if err != nil {
+		return err
+	}
+	if err := fn(cfg); err != nil {
+		return err
+	}
+	after, err := decodeValues(cfg)
+	if err != nil {
+		return err
+	}
+
+	previous, err := os.ReadFile(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	mergeChanges(values, unflatten(before), unflatten(after))
+	values["version"] = CurrentVersion
+	data, err := encodeValues(values, FormatOf(absPath), previous)
+	if err != nil {
+		return fmt.Errorf("cannot update %s: %w", absPath, err)
+	} else if err := os.WriteFile(absPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// mergeChanges applies the changes between two decoded versions of a config
+// to the settings of its file. Settings changed to zero are removed, so that
+// their defaults apply, and so are the sections they leave empty. It reports
+// whether values changed.
+func mergeChanges(values, before, after map[string]any) bool {
+	keys := slices.Collect(maps.Keys(before))
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	changed := false
+	for _, key := range keys {
+		old, value := before[key], after[key]
+		if section, ok := value.(map[string]any); ok {
+			if oldSection, ok := old.(map[string]any); ok || old == nil {
+				settings, _ := values[key].(map[string]any)
+				if settings == nil {
+					settings = make(map[string]any)
+				}
+				if !mergeChanges(settings, oldSection, section) {
+					continue
+				}
+				if len(settings) > 0 {
+					values[key] = settings
+				} else {
+					delete(values, key)
+				}
+				changed = true
+				continue
+			}
+		}
+		if reflect.DeepEqual(old, value) {
+			continue
+		}
+
+		if isZero(value) {
+			delete(values, key)
+		} else if n, ok := value.(float64); ok && n == math.Trunc(n) {
+			values[key] = int(n) // numbers are decoded from JSON as float64
+		} else {
+			values[key] = value
+		}
+		changed = true
+	}
+	return changed
+}
+
 // Reset overwrites the config file with default values
 func Reset() error {
 	return Save(DefaultConfig())
 }
 
+// Dir returns the gitc directory in the user's home, ~/.gitc
+func Dir() string {
+	return filepath.Join(userHomeDir(), ".gitc")
+}
+
 // userHomeDir gets the current user's home directory
 func userHomeDir() string {
 	home, err := os.UserHomeDir()
@@ -0,0 +1,644 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// ------------------- ApplyDefaults -------------------
+
+func TestApplyDefaults_DiffLimits(t *testing.T) {
+	cfg := &Config{Diff: DiffConfig{MaxBytes: -1, MaxFileLines: 100}}
+	ApplyDefaults(cfg)
+
+	want := DiffConfig{MaxBytes: -1, MaxLines: 200000, MaxFileBytes: 2 << 20, MaxFileLines: 100}
+	if cfg.Diff != want {
+		t.Errorf("expected unset limits to take the defaults and -1 to be kept, got %+v", cfg.Diff)
+	}
+}
+
+// ------------------- ParseConvention -------------------
+
+func TestParseConvention(t *testing.T) {
+	conv, err := ParseConvention(`{"types": ["feat", "fix"], "scopes": ["api"], "subject_case": "lower", "prefix": "[{ticket}]"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conv.Types) != 2 || conv.Scopes[0] != "api" || conv.Prefix != "[{ticket}]" {
+		t.Errorf("unexpected convention: %+v", conv)
+	}
+
+	conv, err = ParseConvention("Use past tense")
+	if err != nil || conv.Instructions != "Use past tense" {
+		t.Errorf("expected free-form convention, got %+v, %v", conv, err)
+	}
+
+	if conv, err := ParseConvention(""); conv != nil || err != nil {
+		t.Errorf("expected nil convention for empty input, got %+v, %v", conv, err)
+	}
+}
+
+func TestParseConvention_Errors(t *testing.T) {
+	tests := map[string]string{
+		`{"types": ["feat"`:         "not valid JSON",
+		`{"prefx": "JIRA-1"}`:       "not valid JSON",
+		`{"subject_case": "upper"}`: "subject_case",
+		`{"ticket_pattern": "("}`:   "ticket_pattern",
+		`{"types": ["feat(api)"]}`:  "invalid type",
+		`{"max_header_length": -1}`: "max_header_length",
+	}
+
+	for input, want := range tests {
+		_, err := ParseConvention(input)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseConvention(%s) error = %v, want it to mention %q", input, err, want)
+		}
+	}
+}
+
+// ------------------- YAML -------------------
+
+func TestDecodeYAML(t *testing.T) {
+	doc := `# commitlint
+extends:
+  - "@commitlint/config-conventional"
+rules:
+  type-enum: [2, always, [feat, fix]]   # only these
+  header-max-length:
+    - 2
+    - always
+    - 60
+  plugins:
+    - name: local
+      enabled: true
+notes: |
+  first line
+  second line
+`
+	value, err := decodeYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	root := value.(map[string]any)
+	if extends := root["extends"].([]any); extends[0] != "@commitlint/config-conventional" {
+		t.Errorf("unexpected extends: %v", extends)
+	}
+	rules := root["rules"].(map[string]any)
+	if typeEnum := rules["type-enum"].([]any); typeEnum[0] != 2 || typeEnum[2].([]any)[1] != "fix" {
+		t.Errorf("unexpected type-enum: %v", typeEnum)
+	}
+	if header := rules["header-max-length"].([]any); header[2] != 60 {
+		t.Errorf("unexpected header-max-length: %v", header)
+	}
+	if plugin := rules["plugins"].([]any)[0].(map[string]any); plugin["name"] != "local" || plugin["enabled"] != true {
+		t.Errorf("unexpected plugin: %v", plugin)
+	}
+	if root["notes"] != "first line\nsecond line\n" {
+		t.Errorf("unexpected block scalar: %q", root["notes"])
+	}
+
+	if _, err := decodeYAML([]byte("a: 1\n   b: 2\n")); err == nil {
+		t.Error("expected error for bad indentation")
+	}
+}
+
+func TestDecodeYAML_AnchorsAndNestedLists(t *testing.T) {
+	doc := `level: &error 2
+rules:
+  scope-enum:
+    - *error
+    - always
+    - - api
+      - web
+  type-enum: [*error, always, [feat, fix]]
+`
+	value, err := decodeYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rules := value.(map[string]any)["rules"].(map[string]any)
+	scopeEnum := rules["scope-enum"].([]any)
+	if scopeEnum[0] != 2 || scopeEnum[2].([]any)[1] != "web" {
+		t.Errorf("unexpected scope-enum: %v", scopeEnum)
+	}
+	if typeEnum := rules["type-enum"].([]any); typeEnum[0] != 2 {
+		t.Errorf("expected the alias to resolve to the anchored value, got %v", typeEnum)
+	}
+}
+
+func TestDecodeTOML(t *testing.T) {
+	doc := `# team settings
+language = "fa"  # reviewed in #42
+max_length = 1_000
+use_gitmoji = true
+exclude_files = [
+  "*.snap",  # generated
+  'testdata\*',
+]
+custom_convention = """
+{"types": ["feat", "fix"]}\
+"""
+
+[scope]
+mode = "require"
+rules = [{ pattern = "web/**", scope = "frontend" }]
+
+[[type_hint.rules]]
+pattern = "*.md"
+type = "docs"
+
+[[type_hint.rules]]
+pattern = "ci/**"
+type = "ci"
+
+[profiles."my.work"]
+provider = "grok"
+`
+	value, err := decodeTOML([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	root := value.(map[string]any)
+	if root["language"] != "fa" || root["max_length"] != int64(1000) || root["use_gitmoji"] != true {
+		t.Errorf("unexpected settings: %v", root)
+	}
+	if excludes := root["exclude_files"].([]any); len(excludes) != 2 || excludes[1] != `testdata\*` {
+		t.Errorf("unexpected exclude_files: %v", excludes)
+	}
+	if root["custom_convention"] != `{"types": ["feat", "fix"]}` {
+		t.Errorf("unexpected multi-line string: %q", root["custom_convention"])
+	}
+	if rule := root["scope"].(map[string]any)["rules"].([]any)[0].(map[string]any); rule["scope"] != "frontend" {
+		t.Errorf("unexpected inline table: %v", rule)
+	}
+	if rules := root["type_hint"].(map[string]any)["rules"].([]any); len(rules) != 2 || rules[1].(map[string]any)["type"] != "ci" {
+		t.Errorf("unexpected array of tables: %v", rules)
+	}
+	if profile := root["profiles"].(map[string]any)["my.work"].(map[string]any); profile["provider"] != "grok" {
+		t.Errorf("unexpected quoted table name: %v", profile)
+	}
+
+	for _, bad := range []string{"a = 1\na = 2", "a = ", "a = [1, 2", `a = "open`, "[a\nb = 1"} {
+		if _, err := decodeTOML([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestEncodeFormats(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey = "sk-\"quoted\""
+	cfg.CustomConvention = "{\"prefix\": \"JIRA-1\"}\nsecond: line # not a comment"
+	cfg.Language = "true"
+	cfg.ExcludeFiles = []string{"*.snap", "- dash", "'quote'"}
+	cfg.Scope.Rules = []ScopeRule{{Pattern: "web/**", Scope: "frontend"}, {Pattern: "*.md", Scope: "docs"}}
+	cfg.Validation.Types = []string{"feat", "fix"}
+	cfg.Profiles = map[string]Profile{"work": {Provider: "grok"}, "my.local": {Model: "llama3", URL: "http://localhost:11434"}}
+	cfg.DefaultProfile = "work"
+
+	want, err := Encode(cfg, FormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, format := range []Format{FormatYAML, FormatTOML} {
+		data, err := Encode(cfg, format)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		decoded, err := Parse(data, format)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v\n%s", format, err, data)
+		}
+		if got, _ := Encode(decoded, FormatJSON); string(got) != string(want) {
+			t.Errorf("%s did not round-trip:\n%s\ngot %s", format, data, got)
+		}
+	}
+
+	if FormatOf("config.YML") != FormatYAML || FormatOf(".gitc.toml") != FormatTOML || FormatOf("config") != FormatJSON {
+		t.Error("unexpected format detection")
+	}
+}
+
+// ------------------- commitlint -------------------
+
+func TestLoadCommitlint(t *testing.T) {
+	dir := t.TempDir()
+	if conv, _, err := LoadCommitlint(dir); conv != nil || err != nil {
+		t.Fatalf("expected no convention without configuration, got %+v, %v", conv, err)
+	}
+
+	writeFile(t, dir, "package.json", `{"name": "app", "commitlint": {"extends": ["@commitlint/config-conventional"], "rules": {"scope-enum": [2, "always", ["api", "web"]], "body-max-line-length": [0]}}}`)
+	conv, path, err := LoadCommitlint(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(path, "package.json") || len(conv.Types) != 11 || conv.MaxHeaderLength != 100 {
+		t.Errorf("expected shared config rules, got %+v from %s", conv, path)
+	}
+	if len(conv.Scopes) != 2 || conv.SubjectCase != SubjectCaseLower || conv.MaxBodyLineLength != 0 {
+		t.Errorf("expected local rules to apply, got %+v", conv)
+	}
+
+	// .commitlintrc.yaml takes precedence over package.json
+	writeFile(t, dir, ".commitlintrc.yaml", "rules:\n  type-enum: [2, always, [feat, fix]]\n  subject-case: [2, always, sentence-case]\n  header-max-length: [1, always, 50]\n  scope-enum:\n    - 2\n    - always\n    - - api\n      - web\n      - cli\n")
+	conv, _, err = LoadCommitlint(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conv.Types) != 2 || len(conv.Scopes) != 3 || conv.SubjectCase != SubjectCaseSentence || conv.MaxHeaderLength != 0 {
+		t.Errorf("unexpected convention from YAML: %+v", conv)
+	}
+}
+
+// ------------------- resolution -------------------
+
+func TestResolve(t *testing.T) {
+	home, repo := t.TempDir(), t.TempDir()
+	defer SetConfigPath(configPath)
+	SetConfigPath(filepath.Join(home, "config.json"))
+	writeFile(t, home, "config.json", `{
+		"api_key": "sk-personal", "language": "fa", "timeout": 0,
+		"profiles": {
+			"work": {"provider": "grok", "api_key": "sk-work", "preset": "angular"},
+			"local": {"model": "llama3", "url": "http://localhost:11434/v1/chat/completions"}
+		},
+		"default_profile": "local"
+	}`)
+	env := map[string]string{}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	res, err := Resolve(ResolveOptions{LookupEnv: lookupEnv})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := res.Config; cfg.Model != "llama3" || cfg.Provider != "openai" || cfg.Language != "fa" || cfg.Timeout != 10 {
+		t.Errorf("expected the default profile over the global config, got %+v", cfg)
+	}
+
+	res, err = Resolve(ResolveOptions{Profile: "work", LookupEnv: lookupEnv})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := res.Config; cfg.Provider != "grok" || cfg.Model != "grok-3" || cfg.URL != "https://api.x.ai/v1/chat/completions" || cfg.APIKey != "sk-work" || cfg.Preset != "angular" {
+		t.Errorf("expected the work profile with grok defaults, got %+v", cfg.Profile)
+	}
+	if _, err := Resolve(ResolveOptions{Profile: "home", LookupEnv: lookupEnv}); err == nil || !strings.Contains(err.Error(), "default, local, work") {
+		t.Errorf("expected unknown profile error listing the profiles, got %v", err)
+	}
+
+	writeFile(t, repo, ".gitc.json", `{"api_key": "sk-repo", "url": "https://example.com", "language": "de", "validation": {"max_attempts": 3}, "exclude_files": ["*.snap"]}`)
+	env["GITC_LANGUAGE"] = "ru"
+	env["GITC_MAX_LENGTH"] = "120"
+	res, err = Resolve(ResolveOptions{
+		RepoDir:   repo,
+		Profile:   DefaultProfileName,
+		LookupEnv: lookupEnv,
+		Flags:     []Override{{Key: "model", Value: "gpt-4o", Flag: "model"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(res.RepoFile, ".gitc.json") || strings.Join(res.Ignored, ",") != "api_key,url" {
+		t.Errorf("expected api_key and url to be ignored in %s, got %v", res.RepoFile, res.Ignored)
+	}
+	cfg := res.Config
+	if cfg.APIKey != "sk-personal" || cfg.URL != DefaultConfig().URL {
+		t.Errorf("expected global secrets to be kept, got %q, %q", cfg.APIKey, cfg.URL)
+	}
+	if cfg.Language != "ru" || cfg.MaxLength != 120 || cfg.Model != "gpt-4o" || cfg.Validation.MaxAttempts != 3 || cfg.Validation.Mode != "repair" || cfg.ExcludeFiles[0] != "*.snap" {
+		t.Errorf("expected flags over env over repository over global settings, got %+v", cfg)
+	}
+
+	origins := make(map[string]string)
+	for _, s := range res.Settings() {
+		origins[s.Key] = s.Origin.String()
+	}
+	want := map[string]string{
+		"model":                   "flag (--model)",
+		"language":                "env (GITC_LANGUAGE)",
+		"validation.max_attempts": "repo (" + res.RepoFile + ")",
+		"api_key":                 "global (" + filepath.Join(home, "config.json") + ")",
+		"timeout":                 "default",
+	}
+	for key, origin := range want {
+		if origins[key] != origin {
+			t.Errorf("expected %s from %s, got %s", key, origin, origins[key])
+		}
+	}
+
+	env["GITC_TIMEOUT"] = "soon"
+	if _, err := Resolve(ResolveOptions{LookupEnv: lookupEnv}); err == nil || !strings.Contains(err.Error(), "GITC_TIMEOUT") {
+		t.Errorf("expected invalid environment variable error, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	for key, value := range map[string]string{
+		"proxy":                    "http://proxy:8080",
+		"timeout":                  "30",
+		"use_gitmoji":              "true",
+		"validation.types":         "feat, fix",
+		"scope.rules":              `[{"pattern": "web/**", "scope": "frontend"}]`,
+		"profiles.work.provider":   "grok",
+		"profiles.local.model":     "llama3",
+		"profiles.work.api_key":    "sk-work",
+		"ticket.placement":         "scope",
+		"examples.same_author":     "false",
+		"feedback.max_corrections": "5",
+	} {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("unexpected error setting %s: %v", key, err)
+		}
+	}
+	if cfg.Proxy != "http://proxy:8080" || cfg.Timeout != 30 || !cfg.UseGitmoji || strings.Join(cfg.Validation.Types, ",") != "feat,fix" ||
+		cfg.Scope.Rules[0].Scope != "frontend" || cfg.Profiles["work"].Provider != "grok" || cfg.Profiles["local"].Model != "llama3" {
+		t.Errorf("expected the settings to be set, got %+v", cfg)
+	}
+	if value, err := cfg.Get("ticket.placement"); err != nil || value != "scope" {
+		t.Errorf("expected scope, got %v, %v", value, err)
+	}
+
+	if err := cfg.Set("timeout", "soon"); err == nil || !strings.Contains(err.Error(), "expected a number") {
+		t.Errorf("expected invalid number error, got %v", err)
+	}
+	for _, key := range []string{"diff.context", "ticket.pattern.x", "profiles.work.colour"} {
+		if _, err := cfg.Get(key); err == nil {
+			t.Errorf("expected unknown key error for %s", key)
+		}
+	}
+
+	if err := cfg.Unset("proxy"); err != nil || cfg.Proxy != "" {
+		t.Errorf("expected proxy to be cleared, got %q, %v", cfg.Proxy, err)
+	}
+	if err := cfg.Unset("profiles.local"); err != nil || len(cfg.Profiles) != 1 {
+		t.Errorf("expected the profile to be removed, got %+v, %v", cfg.Profiles, err)
+	}
+
+	settings, err := cfg.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := settings[len(settings)-1]
+	if settings[0].Key != "provider" || last.Key != "profiles.work.api_key" || last.Value != "sk-work" {
+		t.Errorf("expected the settings in file order followed by the profiles, got %+v", settings)
+	}
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "key", "sk-file\n")
+	t.Setenv("TEAM_AI_KEY", "sk-env")
+
+	for _, tc := range []struct {
+		profile Profile
+		want    string
+	}{
+		{Profile{APIKey: "sk-plain"}, "sk-plain"},
+		{Profile{APIKeyCmd: `printf 'sk-cmd\nlogin: me\n'`}, "sk-cmd"},
+		{Profile{APIKeyFile: filepath.Join(dir, "key")}, "sk-file"},
+		{Profile{APIKeyEnv: "TEAM_AI_KEY"}, "sk-env"},
+		{Profile{}, ""},
+	} {
+		if runtime.GOOS == "windows" && tc.profile.APIKeyCmd != "" {
+			continue
+		}
+		if key, err := tc.profile.ResolveAPIKey(); err != nil || key != tc.want {
+			t.Errorf("expected %q from %+v, got %q (%v)", tc.want, tc.profile, key, err)
+		}
+	}
+
+	for _, p := range []Profile{{APIKeyCmd: "exit 1"}, {APIKeyFile: filepath.Join(dir, "missing")}, {APIKeyEnv: "GITC_UNSET_KEY"}} {
+		if _, err := p.ResolveAPIKey(); err == nil {
+			t.Errorf("expected an error from %+v", p)
+		}
+	}
+	if _, err := (&Profile{APIKey: "sk", APIKeyEnv: "TEAM_AI_KEY"}).KeySource(); err == nil || !strings.Contains(err.Error(), "api_key, api_key_env") {
+		t.Errorf("expected an error for two key sources, got %v", err)
+	}
+
+	// A profile's key source replaces the global key
+	defer SetConfigPath(configPath)
+	SetConfigPath(filepath.Join(dir, "config.json"))
+	writeFile(t, dir, "config.json", `{"api_key": "sk-personal", "profiles": {"work": {"api_key_env": "TEAM_AI_KEY"}}}`)
+	res, err := Resolve(ResolveOptions{Profile: "work", LookupEnv: func(string) (string, bool) { return "", false }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source, err := res.Config.KeySource(); source != "api_key_env" || err != nil {
+		t.Errorf("expected the profile's key source, got %q (%v)", source, err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	defer SetConfigPath(configPath)
+	SetConfigPath(path)
+	old := `{
+		"provider": "openai", "language": "fa", "custom-convention": "{\"prefix\": \"JIRA-1\"}",
+		"open_ai": {"api_key": "sk-old", "model": "gpt-4o", "url": ""}
+	}`
+	writeFile(t, dir, "config.json", old)
+	noEnv := func(string) (string, bool) { return "", false }
+
+	res, err := Resolve(ResolveOptions{LookupEnv: noEnv})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg := res.Config; cfg.APIKey != "sk-old" || cfg.Model != "gpt-4o" || cfg.CustomConvention != `{"prefix": "JIRA-1"}` || cfg.Language != "fa" {
+		t.Errorf("expected the open_ai settings at the top level, got %+v", cfg)
+	}
+	m := res.Migration
+	if m == nil || m.From != 0 || m.To != CurrentVersion || len(m.Changes) != 3 {
+		t.Fatalf("expected a migration from version 0 with 3 changes, got %+v", m)
+	}
+	if data, err := os.ReadFile(m.Backup); err != nil || string(data) != old {
+		t.Errorf("expected the original file as backup, got %q (%v)", data, err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Version != CurrentVersion || cfg.APIKey != "sk-old" {
+		t.Errorf("expected the upgraded file to be saved, got %+v", cfg)
+	}
+	if res, err := Resolve(ResolveOptions{LookupEnv: noEnv}); err != nil || res.Migration != nil {
+		t.Errorf("expected no further migration, got %+v (%v)", res.Migration, err)
+	}
+
+	writeFile(t, dir, "config.json", `{"version": 99}`)
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "upgrade gitc") {
+		t.Errorf("expected an error for a newer version, got %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	defer SetConfigPath(configPath)
+	SetConfigPath(filepath.Join(dir, "config.json"))
+	writeFile(t, dir, "config.json", `{"version": 1, "provider": "deepseek", "ticket": {"pattern": "X-[0-9]+"}}`)
+
+	set := func(key, value string) {
+		t.Helper()
+		if err := Update(func(cfg *Config) error { return cfg.Set(key, value) }); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	set("language", "fr")
+	set("timeout", "30")
+	set("profiles.work.model", "gpt-4.1")
+	set("provider", "openai")
+
+	// Only the settings set are written, so the model and URL follow the provider
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	model, url := providerDefaults("openai")
+	if cfg.Provider != "openai" || cfg.Model != model || cfg.URL != url || cfg.Language != "fr" || cfg.Timeout != 30 {
+		t.Errorf("expected the defaults of the new provider, got %+v", cfg)
+	}
+	_, values, _, err := readConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := values["model"]; ok || len(values) != 6 {
+		t.Errorf("expected only the settings set to be written, got %v", values)
+	}
+	if profile := values["profiles"].(map[string]any)["work"]; !reflect.DeepEqual(profile, map[string]any{"model": "gpt-4.1"}) {
+		t.Errorf("expected only the profile's model to be written, got %v", profile)
+	}
+
+	// Unsetting removes the setting and the sections it leaves empty
+	for _, key := range []string{"ticket.pattern", "profiles.work", "timeout"} {
+		if err := Update(func(cfg *Config) error { return cfg.Unset(key) }); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, values, _, _ = readConfig(); len(values) != 3 || values["language"] != "fr" {
+		t.Errorf("expected the unset settings to be removed, got %v", values)
+	}
+
+	if err := Update(func(cfg *Config) error { return cfg.Set("nope", "1") }); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
+
+func TestUpdate_Comments(t *testing.T) {
+	dir := t.TempDir()
+	defer SetConfigPath(configPath)
+
+	// YAML comments and key order are kept, and aliases expanded
+	SetConfigPath(filepath.Join(dir, "config.yaml"))
+	writeFile(t, dir, "config.yaml", `# gitc settings
+version: 1
+language: fa # team language
+types: &types [feat, fix]
+validation:
+  # checked by the hook
+  types: *types
+  mode: fix
+`)
+	err := Update(func(cfg *Config) error {
+		cfg.Language, cfg.Timeout = "de", 30
+		cfg.Validation.Types = append(cfg.Validation.Types, "docs")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	want := `# gitc settings
+version: 1
+language: de # team language
+types: [feat, fix]
+validation:
+  # checked by the hook
+  types:
+    - feat
+    - fix
+    - docs
+  mode: fix
+timeout: 30
+`
+	if string(data) != want {
+		t.Errorf("unexpected YAML:\n%s\nwant:\n%s", data, want)
+	}
+
+	// TOML files are rewritten only without comments
+	SetConfigPath(filepath.Join(dir, "config.toml"))
+	writeFile(t, dir, "config.toml", "version = 1\nlanguage = 'fa' # team language\n")
+	if err := Update(func(cfg *Config) error { return cfg.Set("timeout", "30") }); err == nil || !strings.Contains(err.Error(), "comments") {
+		t.Errorf("expected a TOML file with comments not to be rewritten, got %v", err)
+	}
+	writeFile(t, dir, "config.toml", "version = 1\nlanguage = 'fa'\n")
+	if err := Update(func(cfg *Config) error { return cfg.Set("timeout", "30") }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg, err := Load(); err != nil || cfg.Timeout != 30 || cfg.Language != "fa" {
+		t.Errorf("expected the TOML file to be updated, got %+v (%v)", cfg, err)
+	}
+
+	for doc, want := range map[string]bool{
+		"a = 1 # one\n":          true,
+		"# top\na = 1\n":         true,
+		"a = [\n  1, # one\n]\n": true,
+		"a = '# not'\n":          false,
+		"[t]\na = \"#\"\n":       false,
+	} {
+		if got := hasTOMLComments([]byte(doc)); got != want {
+			t.Errorf("hasTOMLComments(%q) = %v, want %v", doc, got, want)
+		}
+	}
+}
+
+func TestSaveRepo(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".gitc.toml", "language = \"it\"\n\n[scope]\nmode = \"require\"\n")
+
+	path, err := SaveRepo(dir, map[string]any{"preset": "angular", "use_gitmoji": true})
+	if err != nil || filepath.Base(path) != ".gitc.toml" {
+		t.Fatalf("expected the existing file to be updated, got %s (%v)", path, err)
+	}
+	_, values, _, err := readRepo(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for key, want := range map[string]any{"language": "it", "scope.mode": "require", "preset": "angular", "use_gitmoji": true} {
+		if values[key] != want {
+			t.Errorf("expected %s = %v, got %v", key, want, values[key])
+		}
+	}
+
+	if _, err := SaveRepo(dir, map[string]any{"api_key": "sk"}); err == nil {
+		t.Error("expected an error for a setting that may only be set globally")
+	}
+
+	yamlDir := t.TempDir()
+	writeFile(t, yamlDir, ".gitc.yaml", "# shared with the team\nlanguage: it # for reviews\n")
+	if _, err := SaveRepo(yamlDir, map[string]any{"language": "fr"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(yamlDir, ".gitc.yaml")); string(data) != "# shared with the team\nlanguage: fr # for reviews\n" {
+		t.Errorf("expected the comments to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// Subject case values supported by Convention.SubjectCase
+const (
+	SubjectCaseLower    = "lower"    // add login page
+	SubjectCaseSentence = "sentence" // Add login page
+)
+
+// Convention is the structured form of the custom commit message convention,
+// passed as JSON via --custom-convention or the custom_convention setting.
+// It is used both to build the prompt and to validate generated messages.
+type Convention struct {
+	Types             []string `json:"types,omitempty"`
+	Scopes            []string `json:"scopes,omitempty"`
+	RequireScope      bool     `json:"require_scope,omitempty"`
+	SubjectCase       string   `json:"subject_case,omitempty"` // lower or sentence
+	MaxHeaderLength   int      `json:"max_header_length,omitempty"`
+	MaxBodyLineLength int      `json:"max_body_line_length,omitempty"`
+	RequiredFooters   []string `json:"required_footers,omitempty"`
+	TicketPattern     string   `json:"ticket_pattern,omitempty"`
+
+	// Prefix and Suffix are added around the header; "{ticket}" is replaced
+	// by the ticket IDs found in the branch name
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+
+	// Instructions holds a free-form (non-JSON) convention passed to the prompt
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// strictJSON rejects unknown fields so that typos in the convention are reported
+var strictJSON = sonic.Config{DisallowUnknownFields: true}.Froze()
+
+// ParseConvention parses a custom convention. JSON objects are decoded into
+// the schema and validated; any other non-empty text is kept as free-form
+// instructions. An empty string yields a nil convention.
+func ParseConvention(raw string) (*Convention, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(raw, "{") {
+		return &Convention{Instructions: raw}, nil
+	}
+
+	var conv Convention
+	if err := strictJSON.Unmarshal([]byte(raw), &conv); err != nil {
+		return nil, fmt.Errorf("custom convention is not valid JSON: %v", err)
+	}
+	if err := conv.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid custom convention: %w", err)
+	}
+
+	return &conv, nil
+}
+
+// Validate checks the convention values for consistency
+func (c *Convention) Validate() error {
+	switch c.SubjectCase {
+	case "", SubjectCaseLower, SubjectCaseSentence:
+	default:
+		return fmt.Errorf("subject_case must be %q or %q, got %q", SubjectCaseLower, SubjectCaseSentence, c.SubjectCase)
+	}
+
+	if c.MaxHeaderLength < 0 {
+		return errors.New("max_header_length must not be negative")
+	}
+	if c.MaxBodyLineLength < 0 {
+		return errors.New("max_body_line_length must not be negative")
+	}
+	for _, t := range c.Types {
+		if t == "" || strings.ContainsAny(t, " :()") {
+			return fmt.Errorf("invalid type %q in types", t)
+		}
+	}
+	if c.TicketPattern != "" {
+		if _, err := regexp.Compile(c.TicketPattern); err != nil {
+			return fmt.Errorf("invalid ticket_pattern: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// MergeConventions returns base with the fields set in override replacing
+// its own; required footers of both are kept. Either may be nil.
+func MergeConventions(base, override *Convention) *Convention {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+
+	merged := *base
+	if override.Types != nil {
+		merged.Types = override.Types
+	}
+	if override.Scopes != nil {
+		merged.Scopes = override.Scopes
+	}
+	merged.RequireScope = base.RequireScope || override.RequireScope
+	if override.SubjectCase != "" {
+		merged.SubjectCase = override.SubjectCase
+	}
+	if override.MaxHeaderLength > 0 {
+		merged.MaxHeaderLength = override.MaxHeaderLength
+	}
+	if override.MaxBodyLineLength > 0 {
+		merged.MaxBodyLineLength = override.MaxBodyLineLength
+	}
+	merged.RequiredFooters = append(slices.Clone(base.RequiredFooters), override.RequiredFooters...)
+	if override.TicketPattern != "" {
+		merged.TicketPattern = override.TicketPattern
+	}
+	if override.Prefix != "" {
+		merged.Prefix = override.Prefix
+	}
+	if override.Suffix != "" {
+		merged.Suffix = override.Suffix
+	}
+	if override.Instructions != "" {
+		merged.Instructions = override.Instructions
+	}
+
+	return &merged
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// Format is the format of a config file
+type Format string
+
+// Config file formats
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// Formats lists the supported config file formats
+var Formats = []Format{FormatJSON, FormatYAML, FormatTOML}
+
+// ValidFormat reports whether name is a supported config file format
+func ValidFormat(name string) bool {
+	return slices.Contains(Formats, Format(name))
+}
+
+// FormatOf returns the format of a config file by its extension: .yaml and
+// .yml are YAML, .toml is TOML and anything else JSON
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatJSON
+}
+
+// decode parses a config file into its settings
+func (f Format) decode(data []byte) (map[string]any, error) {
+	var value any
+	var err error
+	switch f {
+	case FormatYAML:
+		value, err = decodeYAML(data)
+	case FormatTOML:
+		value, err = decodeTOML(data)
+	default:
+		err = sonic.Unmarshal(data, &value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return map[string]any{}, nil // empty file
+	case map[string]any:
+		return v, nil
+	}
+	return nil, fmt.Errorf("expected a mapping of settings, got %T", value)
+}
+
+// Encode formats a config as a config file of the given format, in the
+// current layout version
+func Encode(cfg *Config, format Format) ([]byte, error) {
+	versioned := *cfg
+	versioned.Version = CurrentVersion
+	cfg = &versioned
+
+	switch format {
+	case FormatYAML:
+		return encodeYAML(ordered(reflect.ValueOf(cfg)), nil)
+	case FormatTOML:
+		return encodeTOML(ordered(reflect.ValueOf(cfg)))
+	}
+	return sonic.MarshalIndent(cfg, "", "  ")
+}
+
+// sortedJSON encodes maps with their keys sorted
+var sortedJSON = sonic.Config{SortMapKeys: true}.Froze()
+
+// encodeValues formats the settings of a config file, as decoded, in the
+// given format. previous is the content of the file they replace: its YAML
+// comments are kept, and TOML files with comments are not rewritten.
+func encodeValues(values map[string]any, format Format, previous []byte) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return encodeYAML(ordered(reflect.ValueOf(values)), previous)
+	case FormatTOML:
+		if hasTOMLComments(previous) {
+			return nil, errTOMLComments
+		}
+		return encodeTOML(ordered(reflect.ValueOf(values)))
+	}
+	return sortedJSON.MarshalIndent(values, "", "  ")
+}
+
+// decodeConfig converts decoded settings into a config
+func decodeConfig(values map[string]any) (*Config, error) {
+	data, err := sonic.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := sonic.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// entry is a key and value of an ordered mapping
+type entry struct {
+	key   string
+	value any
+}
+
+// ordered converts a config value for the YAML and TOML encoders: structs
+// become mappings ([]entry) in field order, maps become mappings sorted by
+// key, slices become []any, nil slices nil, and the rest stays a string,
+// int, bool or float64. Fields tagged omitempty are left out when empty.
+func ordered(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return ordered(v.Elem())
+
+	case reflect.Struct:
+		var entries []entry
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if field.Anonymous {
+				entries = append(entries, ordered(v.Field(i)).([]entry)...)
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() || opts == "omitempty" && v.Field(i).IsZero() {
+				continue
+			}
+			entries = append(entries, entry{name, ordered(v.Field(i))})
+		}
+		return entries
+
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		slices.Sort(keys)
+		entries := make([]entry, 0, len(keys))
+		for _, key := range keys {
+			entries = append(entries, entry{key, ordered(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))})
+		}
+		return entries
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range v.Len() {
+			items[i] = ordered(v.Index(i))
+		}
+		return items
+
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return fmt.Sprint(v.Interface())
+}
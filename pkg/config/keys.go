@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// Get returns the value of a setting by key, e.g. "ticket.pattern" or
+// "profiles.work.model"
+func (c *Config) Get(key string) (any, error) {
+	var value any
+	err := access(reflect.ValueOf(c).Elem(), strings.Split(key, "."), key, func(v reflect.Value) error {
+		value = v.Interface()
+		return nil
+	})
+	return value, err
+}
+
+// Set parses value as the type of the setting and assigns it. Strings are
+// taken as is, other types as JSON, e.g. 5, true or ["feat","fix"]; lists
+// of strings may also be comma separated.
+func (c *Config) Set(key, value string) error {
+	return access(reflect.ValueOf(c).Elem(), strings.Split(key, "."), key, func(v reflect.Value) error {
+		parsed, err := parseSetting(v.Type(), value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		v.Set(parsed)
+		return nil
+	})
+}
+
+// Unset clears a setting, so that its default applies; unsetting a profile
+// removes it
+func (c *Config) Unset(key string) error {
+	return access(reflect.ValueOf(c).Elem(), strings.Split(key, "."), key, func(v reflect.Value) error {
+		v.SetZero()
+		return nil
+	})
+}
+
+// List returns the settings of the config in the order of the config file,
+// followed by the settings of each profile as profiles.<name>.<key>
+func (c *Config) List() ([]Setting, error) {
+	values, err := decodeValues(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings []Setting
+	for _, key := range settingKeys(reflect.TypeOf(Config{}), "") {
+		if value, ok := values[key]; ok && key != "profiles" {
+			settings = append(settings, Setting{Key: key, Value: value})
+		}
+	}
+	for _, name := range c.ProfileNames() {
+		profile, ok := c.Profiles[name]
+		if !ok {
+			continue
+		}
+		values, err := decodeValues(profile)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range settingKeys(reflect.TypeOf(Profile{}), "") {
+			if value := values[key]; !isZero(value) {
+				settings = append(settings, Setting{Key: "profiles." + name + "." + key, Value: value})
+			}
+		}
+	}
+	return settings, nil
+}
+
+// access walks v along path by JSON names and calls fn with the value found.
+// Map entries are copied out and stored back, and deleted once zero.
+func access(v reflect.Value, path []string, key string, fn func(reflect.Value) error) error {
+	if len(path) == 0 || path[0] == "" {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := fieldByJSONName(v, path[0])
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		if len(path) == 1 {
+			return fn(field)
+		}
+		return access(field, path[1:], key, fn)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		name := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(name); existing.IsValid() {
+			elem.Set(existing)
+		}
+
+		var err error
+		if len(path) == 1 {
+			err = fn(elem)
+		} else {
+			err = access(elem, path[1:], key, fn)
+		}
+		if err != nil {
+			return err
+		}
+
+		if elem.IsZero() {
+			if v.Len() > 0 {
+				v.SetMapIndex(name, reflect.Value{})
+			}
+		} else {
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			v.SetMapIndex(name, elem)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown config key %q", key)
+}
+
+// fieldByJSONName returns the field of a struct, or of the structs it
+// embeds, with the given JSON name
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous {
+			if f, ok := fieldByJSONName(v.Field(i), name); ok {
+				return f, true
+			}
+			continue
+		}
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// parseSetting parses a value given on the command line as type t
+func parseSetting(t reflect.Type, value string) (reflect.Value, error) {
+	v := reflect.New(t)
+	if t.Kind() == reflect.String {
+		v.Elem().SetString(value)
+		return v.Elem(), nil
+	}
+
+	err := sonic.Unmarshal([]byte(value), v.Interface())
+	if err != nil && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String {
+		var items []string
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Elem().Set(reflect.ValueOf(items).Convert(t))
+		return v.Elem(), nil
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("expected %s, got %q", typeName(t), value)
+	}
+	return v.Elem(), nil
+}
+
+// typeName describes the JSON type expected for values of type t
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		return "a number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a JSON array"
+	}
+	return "a JSON object"
+}
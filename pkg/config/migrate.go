@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CurrentVersion is the version of the config file layout written by Save
+const CurrentVersion = 1
+
+// Migration describes the upgrade of a config file to the current layout
+type Migration struct {
+	Path    string
+	Backup  string // copy of the file before the upgrade
+	From    int
+	To      int
+	Changes []string
+}
+
+// migrations upgrade the settings of a config file by one version each:
+// migrations[0] upgrades unversioned files to version 1, and so on
+var migrations = []func(values map[string]any) []string{
+	migrateV1,
+}
+
+// migrate upgrades the settings of a config file to the current layout in
+// place and returns the version they had and the changes made
+func migrate(values map[string]any) (int, []string, error) {
+	version := 0
+	switch v := values["version"].(type) {
+	case nil:
+	case float64:
+		version = int(v)
+	case int:
+		version = v
+	case int64:
+		version = int(v)
+	default:
+		return 0, nil, fmt.Errorf("invalid config version %v", v)
+	}
+	if version > CurrentVersion {
+		return version, nil, fmt.Errorf("config version %d is newer than the supported version %d; upgrade gitc", version, CurrentVersion)
+	}
+
+	var changes []string
+	for v := version; v < CurrentVersion; v++ {
+		changes = append(changes, migrations[v](values)...)
+	}
+	if version < CurrentVersion {
+		values["version"] = CurrentVersion
+	}
+	return version, changes, nil
+}
+
+// migrateV1 moves the settings of the nested "open_ai" section, used before
+// providers were configurable, to the top level and renames hyphenated keys.
+// Top-level settings that are already set win.
+func migrateV1(values map[string]any) []string {
+	var changes []string
+	if section, ok := values["open_ai"].(map[string]any); ok {
+		for _, key := range []string{"api_key", "model", "url"} {
+			value := section[key]
+			if isZero(value) {
+				continue
+			}
+			if isZero(values[key]) {
+				values[key] = value
+				changes = append(changes, fmt.Sprintf("moved open_ai.%s to %s", key, key))
+			} else {
+				changes = append(changes, fmt.Sprintf("dropped open_ai.%s, since %s is set", key, key))
+			}
+		}
+		delete(values, "open_ai")
+		if len(changes) == 0 {
+			changes = append(changes, "removed the empty open_ai section")
+		}
+	}
+
+	for old, key := range map[string]string{"custom-convention": "custom_convention"} {
+		value, ok := values[old]
+		if !ok {
+			continue
+		}
+		if isZero(values[key]) {
+			values[key] = value
+			changes = append(changes, fmt.Sprintf("renamed %s to %s", old, key))
+		} else {
+			changes = append(changes, fmt.Sprintf("dropped %s, since %s is set", old, key))
+		}
+		delete(values, old)
+	}
+	return changes
+}
+
+// upgradeConfig migrates the settings read from a config file. When the
+// layout changed, the original file is kept as a backup and the upgraded
+// settings are saved; files that only lack a version are left untouched.
+func upgradeConfig(path string, data []byte, values map[string]any) (*Migration, error) {
+	from, changes, err := migrate(values)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	if _, err := decodeConfig(values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to back up config file: %w", err)
+	}
+
+	// The upgrade goes ahead without the comments TOML cannot keep, since the
+	// backup has them
+	format := FormatOf(path)
+	upgraded, err := encodeValues(values, format, data)
+	if errors.Is(err, errTOMLComments) {
+		changes = append(changes, fmt.Sprintf("dropped the comments, which are kept in %s", filepath.Base(backup)))
+		upgraded, err = encodeValues(values, format, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	} else if err := os.WriteFile(path, upgraded, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	return &Migration{Path: path, Backup: backup, From: from, To: CurrentVersion, Changes: changes}, nil
+}
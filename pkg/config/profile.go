@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultProfileName refers to the top-level profile settings of the config
+const DefaultProfileName = "default"
+
+// ProfileNames returns the names of the available profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := []string{DefaultProfileName}
+	for name := range c.Profiles {
+		if name != DefaultProfileName {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// HasProfile reports whether name is the default profile or a named one
+func (c *Config) HasProfile(name string) bool {
+	_, ok := c.Profiles[name]
+	return ok || name == DefaultProfileName
+}
+
+// profileValues returns the settings the named profile sets over the
+// default profile; the default profile itself sets none
+func (c *Config) profileValues(name string) (map[string]any, error) {
+	if name == "" || name == DefaultProfileName {
+		return nil, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (expected %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	values, err := decodeValues(profile)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		if value == "" {
+			delete(values, key) // not set by the profile
+		}
+	}
+	return values, nil
+}
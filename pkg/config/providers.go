@@ -0,0 +1,28 @@
+package config
+
+// ProviderInfo describes a supported AI provider
+type ProviderInfo struct {
+	Name   string // value of the provider setting
+	Title  string
+	Model  string // default model
+	URL    string // chat completions endpoint
+	KeyEnv string // environment variable its API key is commonly kept in
+}
+
+// Providers are the supported AI providers, all with OpenAI-compatible APIs;
+// others can be used by setting their URL
+var Providers = []ProviderInfo{
+	{"openai", "OpenAI", "gpt-4o-mini", "https://api.openai.com/v1/chat/completions", "OPENAI_API_KEY"},
+	{"grok", "Grok (xAI)", "grok-3", "https://api.x.ai/v1/chat/completions", "XAI_API_KEY"},
+	{"deepseek", "DeepSeek", "deepseek-chat", "https://api.deepseek.com/v1/chat/completions", "DEEPSEEK_API_KEY"},
+}
+
+// LookupProvider returns the supported provider with the given name
+func LookupProvider(name string) (ProviderInfo, bool) {
+	for _, p := range Providers {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ProviderInfo{}, false
+}
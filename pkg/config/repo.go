@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// RepoConfigFiles are the repository configuration files looked up at the
+// repository root, in order; the first one found is used
+var RepoConfigFiles = []string{
+	".gitc.json",
+	".gitc.yaml",
+	".gitc.yml",
+	".gitc.toml",
+	filepath.Join(".gitc", "config.json"),
+	filepath.Join(".gitc", "config.yaml"),
+	filepath.Join(".gitc", "config.yml"),
+	filepath.Join(".gitc", "config.toml"),
+}
+
+// repoForbiddenKeys are only read from the global configuration: the API key
+// is a secret, a committed key command would run on every checkout, a
+// committed URL or proxy could send the key to a third party, and profiles
+// hold all of these
+var repoForbiddenKeys = []string{"api_key", "api_key_cmd", "api_key_file", "api_key_env", "url", "proxy", "profiles"}
+
+// readRepo reads the repository configuration found in dir, usually the
+// repository root. Forbidden keys are left out and returned. It returns the
+// path of the file used, or an empty path if there is none.
+func readRepo(dir string) (string, map[string]any, []string, error) {
+	for _, name := range RepoConfigFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return path, nil, nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		values, err := FormatOf(path).decode(data)
+		if err != nil {
+			return path, nil, nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		var ignored []string
+		for key := range values {
+			if slices.Contains(repoForbiddenKeys, key) {
+				ignored = append(ignored, key)
+				delete(values, key)
+			}
+		}
+		slices.Sort(ignored)
+		return path, flatten(values), ignored, nil
+	}
+
+	return "", nil, nil, nil
+}
+
+// SaveRepo merges settings into the repository configuration found in dir,
+// creating a .gitc.json if there is none, and returns the path of the file
+func SaveRepo(dir string, values map[string]any) (string, error) {
+	path := filepath.Join(dir, RepoConfigFiles[0])
+	settings := make(map[string]any)
+	var previous []byte
+	for _, name := range RepoConfigFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		path, previous = filepath.Join(dir, name), data
+		if settings, err = FormatOf(path).decode(data); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		break
+	}
+
+	for key, value := range values {
+		if slices.Contains(repoForbiddenKeys, key) {
+			return "", fmt.Errorf("%s may only be set in the global config", key)
+		}
+		settings[key] = value
+	}
+
+	data, err := encodeValues(settings, FormatOf(path), previous)
+	if err != nil {
+		return "", fmt.Errorf("cannot update %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
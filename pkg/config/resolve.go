@@ -0,0 +1,358 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// Origin kinds, from the lowest to the highest precedence
+const (
+	OriginDefault = "default"
+	OriginGlobal  = "global"
+	OriginProfile = "profile"
+	OriginRepo    = "repo"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
+)
+
+// Origin tells where an effective setting came from
+type Origin struct {
+	Kind   string
+	Detail string // file, profile, environment variable or flag
+}
+
+// String returns the kind followed by the detail, e.g. "env (GITC_MODEL)"
+func (o Origin) String() string {
+	if o.Detail == "" {
+		return o.Kind
+	}
+	return o.Kind + " (" + o.Detail + ")"
+}
+
+// EnvVar is an environment variable that overrides a setting
+type EnvVar struct {
+	Name string
+	Key  string
+}
+
+// EnvVars are the environment variables read by Resolve
+var EnvVars = []EnvVar{
+	{"AI_PROVIDER", "provider"},
+	{"GITC_MODEL", "model"},
+	{"GITC_API_URL", "url"},
+	{"AI_API_KEY", "api_key"},
+	{"GITC_PROXY", "proxy"},
+	{"GITC_CUSTOM_CONVENTION", "custom_convention"},
+	{"GITC_PRESET", "preset"},
+	{"GITC_MAX_LENGTH", "max_length"},
+	{"GITC_LANGUAGE", "language"},
+	{"GITC_TIMEOUT", "timeout"},
+	{"GITC_COMMIT_TYPE", "commit_type"},
+	{"GITC_GITMOJI", "use_gitmoji"},
+	{"GITC_MAX_REDIRECTS", "max_redirects"},
+	{"GITC_TICKET_PATTERN", "ticket.pattern"},
+	{"GITC_TICKET_PLACEMENT", "ticket.placement"},
+}
+
+// Override is a setting given on the command line
+type Override struct {
+	Key   string // e.g. "ticket.pattern"
+	Value any
+	Flag  string // flag name, without dashes
+}
+
+// ResolveOptions are the sources merged over the global config by Resolve
+type ResolveOptions struct {
+	RepoDir   string                      // repository root; empty outside a repository
+	Profile   string                      // selected profile; empty for default_profile
+	LookupEnv func(string) (string, bool) // os.LookupEnv if nil
+	Flags     []Override                  // flags set on the command line
+}
+
+// Setting is an effective setting
+type Setting struct {
+	Key    string
+	Value  any
+	Origin Origin
+}
+
+// Resolution is the effective configuration and the origin of each setting
+type Resolution struct {
+	Config    *Config
+	RepoFile  string     // repository config used, if any
+	Ignored   []string   // repository settings that may only be set globally
+	Migration *Migration // upgrade of the global config file, if any
+
+	values  map[string]any
+	origins map[string]Origin
+}
+
+// Settings returns the effective settings in the order of the config file.
+// Profiles are left out; their effective settings are the top-level ones.
+func (r *Resolution) Settings() []Setting {
+	var settings []Setting
+	for _, key := range settingKeys(reflect.TypeOf(Config{}), "") {
+		value, ok := r.values[key]
+		if !ok || key == "profiles" {
+			continue
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Origin: r.origins[key]})
+	}
+	return settings
+}
+
+// layer is a set of settings by key, e.g. "ticket.pattern", applied together
+type layer map[string]Setting
+
+// Resolve builds the effective configuration by merging, in order of
+// precedence, the defaults, the global config file, the selected profile,
+// the repository config, the environment variables and the flags. Empty
+// strings and zeros leave settings with a default unchanged, and switching
+// provider without setting the model or URL selects the provider's defaults;
+// likewise, setting an API key source clears the others.
+func Resolve(opts ResolveOptions) (*Resolution, error) {
+	lookupEnv := opts.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	defaults, err := decodeValues(DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	res := &Resolution{values: defaults, origins: make(map[string]Origin)}
+	for key := range defaults {
+		res.origins[key] = Origin{Kind: OriginDefault}
+	}
+
+	// Global config
+	path, globalValues, migration, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	res.Migration = migration
+	global, err := decodeConfig(globalValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	// Repository config
+	var repoValues map[string]any
+	if opts.RepoDir != "" {
+		res.RepoFile, repoValues, res.Ignored, err = readRepo(opts.RepoDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Profile, defaulting to the default_profile of the config files
+	name := opts.Profile
+	if name == "" {
+		name, _ = repoValues["default_profile"].(string)
+	}
+	if name == "" {
+		name = global.DefaultProfile
+	}
+	profileValues, err := global.profileValues(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Environment variables
+	env := make(layer)
+	for _, v := range EnvVars {
+		value, ok := lookupEnv(v.Name)
+		if !ok {
+			continue
+		}
+		parsed, err := parseValue(value, defaults[v.Key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", v.Name, err)
+		}
+		env[v.Key] = Setting{Value: parsed, Origin: Origin{Kind: OriginEnv, Detail: v.Name}}
+	}
+
+	// Flags
+	flags := make(layer)
+	for _, o := range opts.Flags {
+		flags[o.Key] = Setting{Value: o.Value, Origin: Origin{Kind: OriginFlag, Detail: "--" + o.Flag}}
+	}
+
+	res.apply(newLayer(flatten(globalValues), Origin{Kind: OriginGlobal, Detail: path}), defaults)
+	res.apply(newLayer(profileValues, Origin{Kind: OriginProfile, Detail: name}), defaults)
+	res.apply(newLayer(repoValues, Origin{Kind: OriginRepo, Detail: res.RepoFile}), defaults)
+	res.apply(env, defaults)
+	res.apply(flags, defaults)
+
+	if res.Config, err = decodeConfig(unflatten(res.values)); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return res, nil
+}
+
+// newLayer returns a layer of values coming from a single origin
+func newLayer(values map[string]any, origin Origin) layer {
+	l := make(layer, len(values))
+	for key, value := range values {
+		l[key] = Setting{Value: value, Origin: origin}
+	}
+	return l
+}
+
+// apply merges a layer over the resolution
+func (r *Resolution) apply(l layer, defaults map[string]any) {
+	set := func(key string) (Setting, bool) {
+		s, ok := l[key]
+		if !ok || s.Value == nil || isZero(s.Value) && !isZero(defaults[key]) {
+			return Setting{}, false
+		}
+		return s, true
+	}
+
+	// The model and URL belong to the provider
+	if s, ok := set("provider"); ok && fmt.Sprint(s.Value) != fmt.Sprint(r.values["provider"]) {
+		provider := fmt.Sprint(s.Value)
+		model, url := providerDefaults(provider)
+		origin := Origin{Kind: OriginDefault, Detail: "provider " + provider}
+		r.values["model"], r.origins["model"] = model, origin
+		r.values["url"], r.origins["url"] = url, origin
+	}
+
+	// The key is read from a single source
+	for _, source := range APIKeySources {
+		if s, ok := set(source); !ok || isZero(s.Value) {
+			continue
+		}
+		for _, other := range APIKeySources {
+			if _, ok := l[other]; !ok && other != source {
+				delete(r.values, other)
+				delete(r.origins, other)
+			}
+		}
+		break
+	}
+
+	for key := range l {
+		if s, ok := set(key); ok {
+			r.values[key] = s.Value
+			r.origins[key] = s.Origin
+		}
+	}
+}
+
+// isZero reports whether a value is an empty string or zero
+func isZero(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case int:
+		return v == 0
+	case int64:
+		return v == 0
+	}
+	return false
+}
+
+// parseValue converts the value of an environment variable to the type of
+// the setting's default value
+func parseValue(value string, def any) (any, error) {
+	switch def.(type) {
+	case float64:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", value)
+		}
+		return n, nil
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", value)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+// decodeValues converts a config, or part of one, into its flattened
+// settings as decoded from JSON
+func decodeValues(v any) (map[string]any, error) {
+	data, err := sonic.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var values map[string]any
+	if err := sonic.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return flatten(values), nil
+}
+
+// flatten turns the settings of config sections into dotted keys, e.g.
+// {"ticket": {"pattern": ...}} into "ticket.pattern"; profiles are kept
+// whole
+func flatten(values map[string]any) map[string]any {
+	flat := make(map[string]any, len(values))
+	for key, value := range values {
+		if section, ok := value.(map[string]any); ok && key != "profiles" {
+			for name, v := range section {
+				flat[key+"."+name] = v
+			}
+			continue
+		}
+		flat[key] = value
+	}
+	return flat
+}
+
+// unflatten is the inverse of flatten
+func unflatten(flat map[string]any) map[string]any {
+	values := make(map[string]any)
+	for key, value := range flat {
+		section, name, ok := strings.Cut(key, ".")
+		if !ok {
+			if _, isSection := values[key].(map[string]any); !isSection {
+				values[key] = value
+			}
+			continue
+		}
+		m, isSection := values[section].(map[string]any)
+		if !isSection {
+			m = make(map[string]any)
+			values[section] = m
+		}
+		m[name] = value
+	}
+	return values
+}
+
+// settingKeys returns the keys of the settings of a config type, in order
+func settingKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous {
+			keys = append(keys, settingKeys(field.Type, prefix)...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "version" {
+			continue // the layout version of the file, not a setting
+		}
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, settingKeys(field.Type, prefix+name+".")...)
+			continue
+		}
+		keys = append(keys, prefix+name)
+	}
+	return keys
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// errTOMLComments is reported instead of rewriting a TOML file with comments,
+// since the TOML encoder cannot keep them
+var errTOMLComments = errors.New("it has comments, which are lost when a TOML file is rewritten; edit the file instead")
+
+// decodeTOML parses a TOML document. Tables are decoded as map[string]any,
+// arrays as []any and integers as int64.
+func decodeTOML(data []byte) (any, error) {
+	var value map[string]any
+	if err := toml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encodeTOML formats a mapping converted by ordered as a TOML document.
+// Keys are sorted and nil settings are left out, since TOML has no null.
+func encodeTOML(value any) ([]byte, error) {
+	return toml.Marshal(tomlValue(value))
+}
+
+// tomlValue converts a value converted by ordered for the TOML encoder
+func tomlValue(value any) any {
+	switch v := value.(type) {
+	case []entry:
+		table := make(map[string]any, len(v))
+		for _, e := range v {
+			if e.value != nil {
+				table[e.key] = tomlValue(e.value)
+			}
+		}
+		return table
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = tomlValue(item)
+		}
+		return items
+	}
+	return value
+}
+
+// hasTOMLComments reports whether a TOML document has comments
+func hasTOMLComments(data []byte) bool {
+	p := unstable.Parser{KeepComments: true}
+	p.Reset(data)
+	for p.NextExpression() {
+		if hasTOMLComment(p.Expression()) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTOMLComment reports whether a node, its children or the nodes following
+// it, where a comment ending its line is attached, are comments
+func hasTOMLComment(n *unstable.Node) bool {
+	for ; n != nil; n = n.Next() {
+		if n.Kind == unstable.Comment || hasTOMLComment(n.Child()) {
+			return true
+		}
+	}
+	return false
+}
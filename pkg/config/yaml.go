@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeYAML parses a YAML document. Mappings are decoded as map[string]any
+// and sequences as []any.
+func decodeYAML(data []byte) (any, error) {
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encodeYAML formats a value converted by ordered as a YAML document. The
+// document it replaces, if any, is updated instead, so that its comments and
+// the order of its keys are kept; its aliases are expanded.
+func encodeYAML(value any, previous []byte) ([]byte, error) {
+	node := yamlNode(value)
+	var doc yaml.Node
+	if err := yaml.Unmarshal(previous, &doc); err == nil && len(doc.Content) == 1 {
+		inlineAliases(&doc)
+		doc.Content[0] = mergeYAML(doc.Content[0], node)
+		node = &doc
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// yamlNode converts a value converted by ordered into a YAML node, keeping
+// the order of mapping entries
+func yamlNode(value any) *yaml.Node {
+	switch v := value.(type) {
+	case []entry:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, e := range v {
+			node.Content = append(node.Content, yamlNode(e.key), yamlNode(e.value))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	}
+
+	// Scalars are quoted by the encoder when they would read back as another type
+	var node yaml.Node
+	_ = node.Encode(value)
+	return &node
+}
+
+// mergeYAML updates the node old to the value of node and returns it. The
+// keys of mappings keep their order and comments, new keys are added at the
+// end, and nodes replaced keep their comments.
+func mergeYAML(old, node *yaml.Node) *yaml.Node {
+	if sameYAML(old, node) {
+		return old
+	}
+
+	switch {
+	case old.Kind == yaml.MappingNode && node.Kind == yaml.MappingNode:
+		values := make(map[string]*yaml.Node, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			values[node.Content[i].Value] = node.Content[i+1]
+		}
+		var content []*yaml.Node
+		for i := 0; i+1 < len(old.Content); i += 2 {
+			key := old.Content[i]
+			if value, ok := values[key.Value]; ok {
+				content = append(content, key, mergeYAML(old.Content[i+1], value))
+				delete(values, key.Value)
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if _, ok := values[node.Content[i].Value]; ok {
+				content = append(content, node.Content[i], node.Content[i+1])
+			}
+		}
+		old.Content = content
+		return old
+
+	case old.Kind == yaml.SequenceNode && node.Kind == yaml.SequenceNode && len(old.Content) == len(node.Content):
+		for i := range old.Content {
+			old.Content[i] = mergeYAML(old.Content[i], node.Content[i])
+		}
+		return old
+	}
+
+	node.HeadComment, node.LineComment, node.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	return node
+}
+
+// sameYAML reports whether two nodes decode to the same value
+func sameYAML(a, b *yaml.Node) bool {
+	var x, y any
+	return a.Decode(&x) == nil && b.Decode(&y) == nil && reflect.DeepEqual(x, y)
+}
+
+// inlineAliases replaces the aliases under a node with copies of the nodes
+// they refer to and drops the anchors, so that nodes can be changed one by one
+func inlineAliases(n *yaml.Node) {
+	n.Anchor = ""
+	for i, child := range n.Content {
+		if child.Kind == yaml.AliasNode && child.Alias != nil {
+			target := copyYAML(child.Alias)
+			target.HeadComment, target.LineComment, target.FootComment = child.HeadComment, child.LineComment, child.FootComment
+			if n.Kind == yaml.MappingNode && i%2 == 1 && target.Kind != yaml.ScalarNode {
+				// Keep the comment on the line of the key, above the block
+				n.Content[i-1].LineComment, target.LineComment = target.LineComment, ""
+			}
+			n.Content[i] = target
+		}
+		inlineAliases(n.Content[i])
+	}
+}
+
+// copyYAML returns a deep copy of a node
+func copyYAML(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyYAML(child)
+	}
+	return &c
+}
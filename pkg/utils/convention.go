@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rezatg/gitc/pkg/config"
+)
+
+// ticketPlaceholder is replaced by the ticket IDs in convention prefixes and suffixes
+const ticketPlaceholder = "{ticket}"
+
+// getConventionInstructions describes the custom convention for the prompt
+func getConventionInstructions(conv *config.Convention, tickets []string) []string {
+	if conv == nil {
+		return nil
+	}
+
+	var rules []string
+	if conv.Instructions != "" {
+		rules = append(rules, fmt.Sprintf("Follow custom convention: %s", conv.Instructions))
+	}
+	if len(conv.Types) > 0 {
+		rules = append(rules, fmt.Sprintf("Only use these types: %s", strings.Join(conv.Types, ", ")))
+	}
+	switch {
+	case len(conv.Scopes) > 0 && conv.RequireScope:
+		rules = append(rules, fmt.Sprintf("Always add one of these scopes: %s", strings.Join(conv.Scopes, ", ")))
+	case len(conv.Scopes) > 0:
+		rules = append(rules, fmt.Sprintf("If you add a scope, use one of: %s", strings.Join(conv.Scopes, ", ")))
+	case conv.RequireScope:
+		rules = append(rules, "Always add a scope")
+	}
+	switch conv.SubjectCase {
+	case config.SubjectCaseLower:
+		rules = append(rules, "Start the summary with a lower case letter")
+	case config.SubjectCaseSentence:
+		rules = append(rules, "Start the summary with an upper case letter")
+	}
+	if conv.MaxHeaderLength > 0 {
+		// The prefix and suffix are added afterwards and need room too
+		length := max(1, conv.MaxHeaderLength-ConventionAffixLength(conv, tickets))
+		rules = append(rules, fmt.Sprintf("Keep line 1 within %d characters", length))
+	}
+	if conv.MaxBodyLineLength > 0 {
+		rules = append(rules, fmt.Sprintf("Keep body lines within %d characters", conv.MaxBodyLineLength))
+	}
+	if len(conv.RequiredFooters) > 0 {
+		rules = append(rules, fmt.Sprintf("End with these footers: %s", strings.Join(conv.RequiredFooters, ", ")))
+	}
+
+	return rules
+}
+
+// ApplyConventionAffixes adds the convention prefix and suffix to the header.
+// Templates referencing {ticket} are skipped when no ticket ID is known.
+func ApplyConventionAffixes(msg string, conv *config.Convention, tickets []string) string {
+	if conv == nil || (conv.Prefix == "" && conv.Suffix == "") {
+		return msg
+	}
+
+	header, rest, _ := strings.Cut(msg, "\n")
+	if prefix, ok := renderAffix(conv.Prefix, tickets); ok && !strings.HasPrefix(header, prefix) {
+		header = joinAffix(prefix, header)
+	}
+	if suffix, ok := renderAffix(conv.Suffix, tickets); ok && !strings.HasSuffix(header, suffix) {
+		header = joinAffix(header, suffix)
+	}
+
+	return joinMessage(header, rest)
+}
+
+// ConventionAffixLength returns how many characters ApplyConventionAffixes
+// adds to a header, which a header generated without them must leave room for
+func ConventionAffixLength(conv *config.Convention, tickets []string) int {
+	return utf8.RuneCountInString(ApplyConventionAffixes("-", conv, tickets)) - 1
+}
+
+// renderAffix expands the ticket placeholder of a prefix or suffix template
+func renderAffix(template string, tickets []string) (string, bool) {
+	if template == "" {
+		return "", false
+	}
+	if !strings.Contains(template, ticketPlaceholder) {
+		return template, true
+	}
+	if len(tickets) == 0 {
+		return "", false
+	}
+	return strings.ReplaceAll(template, ticketPlaceholder, strings.Join(tickets, ", ")), true
+}
+
+// joinAffix concatenates header parts, adding a space unless the affix
+// already ends (or starts) with whitespace or punctuation like "[" or "]"
+func joinAffix(left, right string) string {
+	if strings.HasSuffix(left, " ") || strings.HasPrefix(right, " ") {
+		return left + right
+	}
+	return left + " " + right
+}
+
+// AffixPattern converts a prefix or suffix template into a regular expression
+// matching its rendered form, so that linting can strip it from the header.
+func AffixPattern(template, ticketPattern string) *regexp.Regexp {
+	if template == "" {
+		return nil
+	}
+	if ticketPattern == "" {
+		ticketPattern = DefaultTicketPattern
+	}
+
+	parts := strings.Split(template, ticketPlaceholder)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(strings.TrimSpace(part))
+	}
+
+	re, err := regexp.Compile(`\s*` + strings.Join(parts, fmt.Sprintf(`(?:%s)(?:, (?:%s))*`, ticketPattern, ticketPattern)) + `\s*`)
+	if err != nil {
+		return nil
+	}
+	return re
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/rezatg/gitc/pkg/validator"
+)
+
+// Example sources controlling where the example messages in the prompt come from
+const (
+	ExampleSourceHistory = "history" // recent commits of the repository that follow the convention
+	ExampleSourceSimilar = "similar" // indexed commits most similar to the staged diff, then recent ones
+	ExampleSourcePreset  = "preset"  // the built-in examples of the convention preset
+)
+
+// MinHistoryExamples is the number of suitable commits needed for the
+// repository history to replace the preset examples, or the number of
+// examples asked for if it is lower
+const MinHistoryExamples = 2
+
+// maxExampleLines skips long messages that would bloat the prompt
+const maxExampleLines = 12
+
+// ValidExampleSource reports whether source is a supported example source
+func ValidExampleSource(source string) bool {
+	switch source {
+	case ExampleSourceHistory, ExampleSourceSimilar, ExampleSourcePreset:
+		return true
+	}
+	return false
+}
+
+// SelectExamples picks up to count messages, in order, that pass the rules.
+// Merge, revert and fixup commits, long messages and repeated headers are
+// skipped, and Signed-off-by trailers are removed since they are not written
+// by the model. Fewer than MinHistoryExamples results, or count if it is
+// lower, yield none.
+func SelectExamples(messages []string, rules validator.Rules, count int) []string {
+	var examples []string
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		if len(examples) >= count {
+			break
+		}
+
+		if validator.Ignored(msg) || len(validator.Validate(msg, rules)) > 0 {
+			continue
+		}
+		msg = stripSignOff(msg)
+		header, _, _ := strings.Cut(msg, "\n")
+		if msg == "" || seen[header] || strings.Count(msg, "\n") >= maxExampleLines {
+			continue
+		}
+
+		seen[header] = true
+		examples = append(examples, msg)
+	}
+
+	if len(examples) < min(MinHistoryExamples, count) || len(examples) == 0 {
+		return nil
+	}
+	return examples
+}
+
+// stripSignOff removes Signed-off-by trailers from a message
+func stripSignOff(msg string) string {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "Signed-off-by:") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
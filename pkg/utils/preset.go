@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"github.com/rezatg/gitc/pkg/validator"
+)
+
+// Names of the built-in convention presets
+const (
+	PresetConventional = "conventional" // feat(api): add login page
+	PresetAngular      = "angular"      // feat(router): add lazy route guard
+	PresetGitmoji      = "gitmoji"      // ✨ Add login page
+	PresetKernel       = "kernel"       // net/ipv4: fix checksum offload
+	PresetChromium     = "chromium"     // Omnibox: Trim pasted URLs + Bug: footer
+	PresetPlain        = "plain"        // Add login page
+)
+
+// DefaultPreset is the preset used when none is configured
+const DefaultPreset = PresetConventional
+
+// AngularTypes are the commit types of the Angular convention
+var AngularTypes = []string{"build", "ci", "docs", "feat", "fix", "perf", "refactor", "test"}
+
+// Preset is a built-in commit message convention. It provides the header
+// format, instructions and examples used in the prompt, and the validator
+// style and rules generated messages are checked against.
+type Preset struct {
+	Name        string
+	Description string
+	Style       string   // validator header style
+	Format      string   // format of line 1 shown to the model
+	Types       []string // allowed types of the conventional style, DefaultTypes when nil
+	SubjectCase string   // "lower", "sentence" or empty for any
+	Footers     []string // footers every message must end with
+
+	// SignOff means commits carry a Signed-off-by footer added by `git commit -s`
+	SignOff bool
+	// TicketFooter is the footer token ticket IDs are added with, "Refs" when empty
+	TicketFooter string
+
+	Rules    []string // preset specific prompt instructions
+	Examples []string // at least two example messages, the first with a body
+}
+
+// Presets lists the built-in convention presets
+var Presets = []Preset{
+	{
+		Name:        PresetConventional,
+		Description: "Conventional Commits: type(scope): summary",
+		Style:       validator.StyleConventional,
+		Format:      "<type>: <summary>",
+		Rules:       []string{"Follow Conventional Commits"},
+		Examples: []string{
+			"feat: add JWT middleware\n\nAdd access token check to protected routes.",
+			"fix: prevent crash on nil DB config",
+		},
+	},
+	{
+		Name:        PresetAngular,
+		Description: "Angular: type(scope): summary in lower case with Angular types",
+		Style:       validator.StyleConventional,
+		Format:      "<type>(<scope>): <summary>",
+		Types:       AngularTypes,
+		SubjectCase: "lower",
+		Rules: []string{
+			"Follow the Angular commit message guidelines",
+			"Start the summary with a lower case letter",
+			"Describe breaking changes in a 'BREAKING CHANGE: ' footer",
+		},
+		Examples: []string{
+			"fix(compiler): handle templates without nodes\n\nReturn an empty view instead of throwing for empty templates.",
+			"feat(router): add guard for lazy loaded routes",
+		},
+	},
+	{
+		Name:        PresetGitmoji,
+		Description: "Gitmoji first: <gitmoji> Summary",
+		Style:       validator.StyleGitmoji,
+		Format:      "<gitmoji> <summary>",
+		SubjectCase: "sentence",
+		Rules: []string{
+			"Start with the one Gitmoji that best fits the change: ✨ feature, 🐛 bug fix, 🚑️ hotfix, 📝 docs, ♻️ refactor, ⚡️ performance, ✅ tests, 🎨 structure, 🔧 configuration, ⬆️ dependencies, 🔥 removal, 🔒️ security",
+			"Do not add a type or scope after the Gitmoji",
+		},
+		Examples: []string{
+			"✨ Add JWT middleware\n\nAdd access token check to protected routes.",
+			"🐛 Prevent crash on nil DB config",
+		},
+	},
+	{
+		Name:        PresetKernel,
+		Description: "Linux kernel: subsystem: summary, signed off",
+		Style:       validator.StyleSubsystem,
+		Format:      "<subsystem>: <summary>",
+		SubjectCase: "lower",
+		SignOff:     true,
+		Rules: []string{
+			"Prefix the summary with the affected subsystem, driver or path (e.g. net/ipv4, mm, drm/i915)",
+			"Explain what was wrong and why this change fixes it in the body",
+			"Do not add a Signed-off-by footer; it is added by git commit -s",
+		},
+		Examples: []string{
+			"net/ipv4: fix checksum of fragmented packets\n\nThe checksum was computed before the fragment offset was applied,\nso reassembled packets were dropped by the receiver.",
+			"mm: remove unused page flag helper",
+		},
+	},
+	{
+		Name:         PresetChromium,
+		Description:  "Chromium: Summary with a Bug: footer",
+		Style:        validator.StylePlain,
+		Format:       "<component>: <summary> or <summary>",
+		Footers:      []string{"Bug"},
+		TicketFooter: "Bug",
+		Rules: []string{
+			"Prefix the summary with the component when it is obvious (e.g. Omnibox: )",
+			"Explain the motivation for the change in the body",
+		},
+		Examples: []string{
+			"Fix crash when closing the last tab\n\nCheck that the tab strip still has a model before notifying observers.\n\nBug: None",
+			"Omnibox: Trim whitespace from pasted URLs\n\nBug: None",
+		},
+	},
+	{
+		Name:        PresetPlain,
+		Description: "Plain imperative summary without prefix",
+		Style:       validator.StylePlain,
+		Format:      "<summary>",
+		SubjectCase: "sentence",
+		Rules:       []string{"Do not add a type, scope or other prefix to the summary"},
+		Examples: []string{
+			"Add JWT middleware\n\nAdd access token check to protected routes.",
+			"Prevent crash on nil DB config",
+		},
+	},
+}
+
+// LookupPreset returns the built-in preset with the given name. An empty name
+// yields DefaultPreset.
+func LookupPreset(name string) (Preset, bool) {
+	if name == "" {
+		name = DefaultPreset
+	}
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// ValidPreset reports whether name is a built-in preset or empty
+func ValidPreset(name string) bool {
+	_, ok := LookupPreset(name)
+	return ok
+}
+
+// PresetNames returns the names of the built-in presets
+func PresetNames() []string {
+	names := make([]string, len(Presets))
+	for i, preset := range Presets {
+		names[i] = preset.Name
+	}
+	return names
+}
+
+// Conventional reports whether the preset uses typed Conventional Commits
+// headers, which scope inference, type hints and Gitmoji build on.
+func (p Preset) Conventional() bool {
+	return p.Style == validator.StyleConventional
+}
+
+// TicketPlacement returns where ticket IDs go with the preset. Only typed
+// Conventional Commits headers have a scope to hold them; other presets,
+// whose headers start with a subsystem or a plain subject, use the footer.
+func (p Preset) TicketPlacement(placement string) string {
+	if placement == TicketPlacementScope && !p.Conventional() {
+		return TicketPlacementFooter
+	}
+	return placement
+}
+
+// ValidationTypes returns the commit types allowed by the preset, preferring
+// explicitly configured types.
+func (p Preset) ValidationTypes(configured []string) []string {
+	if configured != nil {
+		return configured
+	}
+	if p.Types != nil {
+		return p.Types
+	}
+	return validator.DefaultTypes
+}
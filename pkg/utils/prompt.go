@@ -3,54 +3,168 @@ package utils
 import (
 	"fmt"
 	"strings"
+
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/rezatg/gitc/pkg/validator"
 )
 
-func GetPromptForSingleCommit(diff, commitType, customMessageConvention, language string) string {
-	language = strings.ToLower(strings.TrimSpace(language))
+// PromptOptions holds the inputs used to build a commit message prompt
+type PromptOptions struct {
+	Templates     *PromptTemplates // nil uses the built-in templates
+	Preset        string           // built-in convention preset, DefaultPreset when empty
+	Files         []string         // staged file paths
+	Branch        string
+	Language      string
+	CommitType    string
+	Convention    *config.Convention
+	Tickets       []string // ticket IDs injected into the message after generation
+	Scope         string   // scope inferred from the staged files
+	ScopeRequired bool     // whether Scope must be used rather than suggested
+	TypeHint      string   // commit type inferred from the staged files
+	TypeRequired  bool     // whether TypeHint must be used rather than suggested
+	Types         []string // allowed commit types, defaults to validator.DefaultTypes
+	Examples      []string // messages from the repository history replacing the preset examples
+	Corrections   []Correction
+
+	// Set when re-prompting after the previous message failed validation
+	Previous   string
+	Violations []string
+}
+
+// GetSystemPrompt renders the system prompt sent with every request
+func GetSystemPrompt(opts PromptOptions) (string, error) {
+	return opts.Templates.Render(TemplateSystem, promptData("", opts))
+}
+
+// GetPromptForSingleCommit renders the prompt for a commit message of the diff
+func GetPromptForSingleCommit(diff string, opts PromptOptions) (string, error) {
+	return opts.Templates.Render(TemplateCommit, promptData(diff, opts))
+}
+
+// GetPromptForCommitPlan renders the prompt asking to cluster numbered hunks
+// into logical commits, answered as JSON.
+func GetPromptForCommitPlan(hunks string, opts PromptOptions) (string, error) {
+	return opts.Templates.Render(TemplateSplit, promptData(hunks, opts))
+}
+
+// promptData builds the template data model from the prompt options
+func promptData(diff string, opts PromptOptions) PromptData {
+	language := strings.ToLower(strings.TrimSpace(opts.Language))
 	if language == "" {
 		language = "en"
 	}
+	preset := promptPreset(opts.Preset)
 
-	return fmt.Sprintf(`Write a concise Git commit message in %s based on this diff:
-
-	%s
+	// History examples are selected by SelectExamples, which requires enough of them
+	examples := preset.Examples
+	if len(opts.Examples) > 0 {
+		examples = opts.Examples
+	}
 
-	Format:
-	Line 1: <type>: <summary> (≤50 chars)
-	Line 2: (blank)
-	Line 3+: (optional) details (≤100 chars per line)
+	commitType := opts.CommitType
+	if commitType == "" {
+		commitType = opts.TypeHint
+	}
 
-	Rules:
-	- Use imperative mood (e.g. Add, Fix, Refactor)
-	- Be clear and specific
-	- %s
-	- %s
-	- No emoji, quotes, Markdown, or explanations
+	return PromptData{
+		Diff:          diff,
+		Files:         opts.Files,
+		Branch:        opts.Branch,
+		Language:      language,
+		Preset:        preset,
+		Convention:    opts.Convention,
+		Format:        getHeaderFormat(preset, opts.Scope, opts.ScopeRequired),
+		Type:          commitType,
+		TypeRequired:  opts.CommitType != "" || opts.TypeRequired,
+		Types:         preset.ValidationTypes(opts.Types),
+		Scope:         opts.Scope,
+		ScopeRequired: opts.ScopeRequired,
+		Tickets:       opts.Tickets,
+		Rules:         getPresetInstructions(preset, opts),
+		Examples:      examples,
+		Corrections:   opts.Corrections,
+		Previous:      opts.Previous,
+		Violations:    opts.Violations,
+	}
+}
 
-	Examples:
-	feat: add JWT middleware
+// promptPreset looks up the preset for a prompt, falling back to DefaultPreset
+func promptPreset(name string) Preset {
+	if preset, ok := LookupPreset(name); ok {
+		return preset
+	}
+	preset, _ := LookupPreset(DefaultPreset)
+	return preset
+}
 
-	Add access token check to protected routes.
+// getPresetInstructions collects the rules of the preset, the custom
+// convention and the resolved type, scope and ticket IDs
+func getPresetInstructions(preset Preset, opts PromptOptions) []string {
+	var rules []string
+	if preset.Conventional() {
+		rules = append(rules,
+			getTypeInstruction(opts.CommitType, opts.TypeHint, opts.TypeRequired, preset.ValidationTypes(opts.Types)),
+			getScopeInstruction(opts.Scope, opts.ScopeRequired))
+	}
+	rules = append(rules, preset.Rules...)
+	rules = append(rules, getConventionInstructions(opts.Convention, opts.Tickets)...)
+	rules = append(rules, getFooterInstructions(preset, opts.Tickets)...)
+	rules = append(rules, getTicketInstruction(opts.Tickets))
 
-	fix: prevent crash on nil DB config
+	if preset.Style == validator.StyleGitmoji {
+		return append(rules, "No other emoji, quotes, Markdown, or explanations")
+	}
+	return append(rules, "No emoji, quotes, Markdown, or explanations")
+}
 
-	Add nil check before DB usage.`,
-		language,
-		diff,
-		getTypeInstruction(commitType),
-		getConventionInstruction(customMessageConvention))
+// getFooterInstructions asks for the footers the preset requires. Footers
+// holding ticket IDs are left out when the IDs are added automatically.
+func getFooterInstructions(preset Preset, tickets []string) []string {
+	var rules []string
+	for _, token := range preset.Footers {
+		if token == preset.TicketFooter && len(tickets) > 0 {
+			continue
+		}
+		rules = append(rules, fmt.Sprintf("End with a '%s: ' footer; use '%s: None' if the diff does not tell", token, token))
+	}
+	return rules
 }
 
-func getTypeInstruction(commitType string) string {
-	if commitType != "" {
+func getTypeInstruction(commitType, typeHint string, required bool, types []string) string {
+	switch {
+	case commitType != "":
 		return fmt.Sprintf("Use type '%s'", commitType)
+	case typeHint != "" && required:
+		return fmt.Sprintf("Use type '%s'", typeHint)
+	case typeHint != "":
+		return fmt.Sprintf("Use type '%s' unless the diff clearly calls for another type; all changed files suggest it", typeHint)
+	}
+	if len(types) == 0 {
+		types = validator.DefaultTypes
+	}
+	return fmt.Sprintf("Choose appropriate type (%s)", strings.Join(types, ", "))
+}
+
+func getHeaderFormat(preset Preset, scope string, required bool) string {
+	if preset.Conventional() && scope != "" && required {
+		return fmt.Sprintf("<type>(%s): <summary>", scope)
+	}
+	return preset.Format
+}
+
+func getScopeInstruction(scope string, required bool) string {
+	switch {
+	case scope != "" && required:
+		return fmt.Sprintf("Use scope '%s'", scope)
+	case scope != "":
+		return fmt.Sprintf("Use scope '%s' if it fits the change, e.g. <type>(%s): <summary>", scope, scope)
 	}
-	return "Choose appropriate type (feat, fix, docs, style, refactor, test, chore, build, ci, revert, init, security)"
+	return "Add a scope only if it is obvious from the diff"
 }
 
-func getConventionInstruction(convention string) string {
-	if convention != "" {
-		return fmt.Sprintf("Follow custom convention: %s", convention)
+func getTicketInstruction(tickets []string) string {
+	if len(tickets) > 0 {
+		return fmt.Sprintf("Do not mention ticket IDs (%s); they are added automatically", strings.Join(tickets, ", "))
 	}
-	return "Follow Conventional Commits"
+	return "Do not invent ticket or issue IDs"
 }
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rezatg/gitc/pkg/validator"
+)
+
+// Scope modes controlling how an inferred scope is used
+const (
+	ScopeModeOff     = "off"     // do not infer a scope
+	ScopeModeSuggest = "suggest" // pass the scope to the prompt as a suggestion
+	ScopeModeRequire = "require" // require the scope and enforce it in the output
+)
+
+// ScopeRule maps files matching a glob pattern to a commit scope.
+// Patterns use path.Match syntax with an additional "**" wildcard
+// that matches any number of directories.
+type ScopeRule struct {
+	Pattern string
+	Scope   string
+}
+
+// ValidScopeMode reports whether mode is a supported scope mode
+func ValidScopeMode(mode string) bool {
+	switch mode {
+	case ScopeModeOff, ScopeModeSuggest, ScopeModeRequire:
+		return true
+	}
+	return false
+}
+
+// InferScope computes the commit scope from the staged files. Each file is
+// mapped by the first matching rule, falling back to the first directory under
+// root. A scope is returned only when all mapped files agree on it; otherwise
+// the distinct candidates are returned for reference.
+func InferScope(files []string, root string, rules []ScopeRule) (string, []string) {
+	root = strings.Trim(root, "/")
+	counts := make(map[string]int)
+
+	for _, file := range files {
+		if scope := scopeForFile(file, root, rules); scope != "" {
+			counts[scope]++
+		}
+	}
+
+	candidates := make([]string, 0, len(counts))
+	for scope := range counts {
+		candidates = append(candidates, scope)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if counts[candidates[i]] != counts[candidates[j]] {
+			return counts[candidates[i]] > counts[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	if len(candidates) == 1 {
+		return candidates[0], candidates
+	}
+	return "", candidates
+}
+
+// scopeForFile maps a single file to a scope
+func scopeForFile(file, root string, rules []ScopeRule) string {
+	for _, rule := range rules {
+		if MatchGlob(rule.Pattern, file) {
+			return rule.Scope
+		}
+	}
+
+	rel := file
+	if root != "" {
+		if !strings.HasPrefix(file, root+"/") {
+			return ""
+		}
+		rel = strings.TrimPrefix(file, root+"/")
+	}
+
+	dir, _, found := strings.Cut(rel, "/")
+	if !found {
+		return "" // files directly under the root have no scope
+	}
+	return dir
+}
+
+// MatchGlob reports whether name matches the glob pattern. Besides the
+// path.Match syntax, "**" matches any number of path segments and a pattern
+// without a slash matches the base name of the file.
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "**") {
+		if !strings.Contains(pattern, "/") {
+			ok, _ := path.Match(pattern, path.Base(name))
+			return ok
+		}
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
+
+// globToRegexp converts a glob pattern with "**" support to a regular expression
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					builder.WriteString("(?:.*/)?")
+				} else {
+					builder.WriteString(".*")
+				}
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	builder.WriteString("$")
+	return regexp.Compile(builder.String())
+}
+
+// EnforceScope rewrites the scope of a Conventional Commits header so that it
+// matches scope. Messages without a recognizable header are returned unchanged.
+func EnforceScope(msg, scope string) string {
+	header, rest, _ := strings.Cut(msg, "\n")
+	h := validator.ParseHeader(header)
+	if !h.WellFormed || h.Scope == scope {
+		return msg
+	}
+
+	h.Scope = scope
+	return joinMessage(h.FormatHeader(validator.StyleConventional), rest)
+}
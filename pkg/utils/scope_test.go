@@ -0,0 +1,62 @@
+package utils
+
+import "testing"
+
+// ------------------- scope -------------------
+
+func TestInferScope(t *testing.T) {
+	scope, _ := InferScope([]string{"packages/billing/a.go", "packages/billing/b/c.go"}, "packages", nil)
+	if scope != "billing" {
+		t.Errorf("expected scope billing, got %q", scope)
+	}
+
+	scope, candidates := InferScope([]string{"packages/billing/a.go", "packages/auth/b.go"}, "packages", nil)
+	if scope != "" || len(candidates) != 2 {
+		t.Errorf("expected no scope for mixed change, got %q %v", scope, candidates)
+	}
+
+	rules := []ScopeRule{{Pattern: "web/**", Scope: "frontend"}}
+	if scope, _ := InferScope([]string{"web/src/app.ts"}, "", rules); scope != "frontend" {
+		t.Errorf("expected scope frontend, got %q", scope)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/api/guide.md", false},
+		{"web/**", "web/src/app.ts", true},
+		{"**/testdata/**", "internal/git/testdata/a.diff", true},
+		{"**/*_test.go", "main_test.go", true},
+		{"web/**/*.css", "web/a.ts", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnforceScope(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"feat(api)!: drop v1\n\nBody.", "feat(billing)!: drop v1\n\nBody."},
+		{"fix: typo: readme", "fix(billing): typo: readme"},
+		{"🐛 fix(billing): close pool", "🐛 fix(billing): close pool"},
+		{"Update README: fix link", "Update README: fix link"},
+	}
+
+	for _, tt := range tests {
+		if got := EnforceScope(tt.msg, "billing"); got != tt.want {
+			t.Errorf("EnforceScope(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
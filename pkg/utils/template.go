@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/rezatg/gitc/pkg/config"
+)
+
+// Names of the prompt templates; a template directory overrides them with
+// files of the same name
+const (
+	TemplateSystem = "system.tmpl" // system prompt sent with every request
+	TemplateCommit = "commit.tmpl" // prompt for a single commit message
+	TemplateSplit  = "split.tmpl"  // prompt for splitting hunks into commits
+)
+
+// TemplateBuiltin is reported as the source of templates not loaded from a file
+const TemplateBuiltin = "built-in"
+
+// PromptData is the data model prompt templates are rendered with
+type PromptData struct {
+	Diff     string   // staged diff, or the numbered hunks for split.tmpl
+	Files    []string // staged file paths relative to the repository root
+	Branch   string   // current branch, empty when HEAD is detached
+	Language string   // language of the message, e.g. "en"
+
+	Preset     Preset             // convention preset with its rules and examples
+	Convention *config.Convention // custom convention, nil if none
+	Format     string             // format of line 1, e.g. "<type>(api): <summary>"
+
+	Type          string   // requested or inferred commit type, if any
+	TypeRequired  bool     // whether Type must be used rather than suggested
+	Types         []string // allowed commit types
+	Scope         string   // requested or inferred scope, if any
+	ScopeRequired bool     // whether Scope must be used rather than suggested
+	Tickets       []string // ticket IDs added to the message after generation
+
+	Rules       []string     // instructions derived from all of the above
+	Examples    []string     // example messages
+	Corrections []Correction // earlier generated messages the user edited before committing
+
+	// Set when re-prompting after the previous message failed validation
+	Previous   string
+	Violations []string
+}
+
+// Correction is a generated message and the message committed instead
+type Correction struct {
+	Generated string
+	Final     string
+}
+
+// defaultTemplates are the built-in prompt templates
+var defaultTemplates = map[string]string{
+	TemplateSystem: `You are an AI assistant that generates concise and meaningful Git commit messages.`,
+
+	TemplateCommit: `Write a concise Git commit message in {{.Language}} based on this diff:
+
+{{.Diff}}
+
+Format:
+Line 1: {{.Format}} (≤50 chars)
+Line 2: (blank)
+Line 3+: (optional) details (≤100 chars per line)
+
+Rules:
+- Use imperative mood (e.g. Add, Fix, Refactor)
+- Be clear and specific
+{{- range .Rules}}
+- {{.}}
+{{- end}}
+
+Examples:
+{{join .Examples "\n\n"}}
+{{- if .Corrections}}
+
+The user edited earlier messages for similar changes before committing; follow their preferences:
+{{- range .Corrections}}
+
+Generated:
+{{.Generated}}
+Committed:
+{{.Final}}
+{{- end}}
+{{- end}}
+{{- if .Violations}}
+
+Your previous message was:
+{{.Previous}}
+
+It broke these rules; write a new message that fixes all of them:
+{{- range .Violations}}
+- {{.}}
+{{- end}}
+{{- end}}`,
+
+	TemplateSplit: `Group these staged hunks into coherent, logical Git commits and write a commit message in {{.Language}} for each group:
+
+{{.Diff}}
+
+Rules:
+- Every hunk ID must appear in exactly one group
+- Hunks of the same file may go to different groups when they are unrelated
+- Order groups so that each commit builds on the previous ones
+- Prefer fewer groups; do not split a single logical change
+- Each message: {{.Format}} (≤50 chars), optional blank line and details
+- Use imperative mood (e.g. Add, Fix, Refactor)
+{{- range .Rules}}
+- {{.}}
+{{- end}}
+
+Reply with JSON only, no Markdown:
+{{- if gt (len .Examples) 1}}
+{"commits": [{"hunks": [1, 3], "message": {{quote (index .Examples 0)}}}, {"hunks": [2], "message": {{quote (index .Examples 1)}}}]}
+{{- else}}
+{"commits": [{"hunks": [1, 2, 3], "message": {{if .Examples}}{{quote (index .Examples 0)}}{{else}}{{quote .Format}}{{end}}}]}
+{{- end}}`,
+}
+
+// templateFuncs are the functions available to prompt templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"quote": strconv.Quote,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"indent": func(prefix, text string) string {
+		return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+	},
+}
+
+// PromptTemplates holds the prompt templates: the built-in ones, overridden
+// by the *.tmpl files of the template directories
+type PromptTemplates struct {
+	tmpl    *template.Template
+	sources map[string]string // template name to the file it was loaded from
+}
+
+// builtinTemplates are used when no template directory is configured
+var builtinTemplates = mustLoadTemplates()
+
+func mustLoadTemplates() *PromptTemplates {
+	templates, err := LoadPromptTemplates()
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}
+
+// LoadPromptTemplates loads the built-in templates and overrides them with
+// the *.tmpl files found in dirs, later directories taking precedence.
+// Missing directories are skipped. Files may also {{define}} templates used
+// by other files.
+func LoadPromptTemplates(dirs ...string) (*PromptTemplates, error) {
+	t := &PromptTemplates{
+		tmpl:    template.New("").Funcs(templateFuncs).Option("missingkey=error"),
+		sources: make(map[string]string),
+	}
+	for _, name := range []string{TemplateSystem, TemplateCommit, TemplateSplit} {
+		if _, err := t.tmpl.New(name).Parse(defaultTemplates[name]); err != nil {
+			return nil, fmt.Errorf("invalid built-in template %s: %w", name, err)
+		}
+		t.sources[name] = TemplateBuiltin
+	}
+
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template: %w", err)
+			}
+			if err := t.parse(path, string(data)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// parse adds a template file and records it as the source of every prompt
+// template it defines or redefines
+func (t *PromptTemplates) parse(path, text string) error {
+	trees := make(map[string]*parse.Tree)
+	for name := range t.sources {
+		trees[name] = t.tmpl.Lookup(name).Tree
+	}
+
+	name := filepath.Base(path)
+	if _, err := t.tmpl.New(name).Parse(text); err != nil {
+		return fmt.Errorf("invalid template %s: %w", path, err)
+	}
+
+	t.sources[name] = path
+	for other, tree := range trees {
+		if t.tmpl.Lookup(other).Tree != tree {
+			t.sources[other] = path
+		}
+	}
+	return nil
+}
+
+// Source returns the file a template was loaded from, or TemplateBuiltin
+func (t *PromptTemplates) Source(name string) string {
+	if t == nil {
+		t = builtinTemplates
+	}
+	return t.sources[name]
+}
+
+// Render executes the named template with the data
+func (t *PromptTemplates) Render(name string, data PromptData) (string, error) {
+	if t == nil {
+		t = builtinTemplates
+	}
+
+	var buf bytes.Buffer
+	if err := t.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rezatg/gitc/pkg/validator"
+)
+
+// DefaultTicketPattern matches issue tracker keys such as PAY-1234
+const DefaultTicketPattern = `[A-Z][A-Z0-9]+-[0-9]+`
+
+// Supported placements for ticket IDs in the commit message
+const (
+	TicketPlacementPrefix = "prefix" // PAY-1234 feat: add refund flow
+	TicketPlacementScope  = "scope"  // feat(PAY-1234): add refund flow
+	TicketPlacementFooter = "footer" // Refs: PAY-1234
+)
+
+// ValidTicketPlacement reports whether placement is a supported ticket placement
+func ValidTicketPlacement(placement string) bool {
+	switch placement {
+	case TicketPlacementPrefix, TicketPlacementScope, TicketPlacementFooter:
+		return true
+	}
+	return false
+}
+
+// ExtractTickets finds all ticket IDs in a branch name using the given pattern.
+// An empty pattern falls back to DefaultTicketPattern.
+func ExtractTickets(branch, pattern string) ([]string, error) {
+	if pattern == "" {
+		pattern = DefaultTicketPattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket pattern %q: %w", pattern, err)
+	}
+
+	var tickets []string
+	seen := make(map[string]bool)
+	for _, match := range re.FindAllStringSubmatch(branch, -1) {
+		// Prefer the first capture group so patterns can strip surrounding text
+		ticket := match[0]
+		if len(match) > 1 && match[1] != "" {
+			ticket = match[1]
+		}
+		if !seen[ticket] {
+			seen[ticket] = true
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	return tickets, nil
+}
+
+// ApplyTickets injects ticket IDs into a commit message at the given placement.
+// Footers use the given token, "Refs" when empty. Tickets already mentioned in
+// the message are not added again.
+func ApplyTickets(msg string, tickets []string, placement, footer string) string {
+	var missing []string
+	for _, ticket := range tickets {
+		if !strings.Contains(msg, ticket) {
+			missing = append(missing, ticket)
+		}
+	}
+	if len(missing) == 0 {
+		return msg
+	}
+
+	header, rest, _ := strings.Cut(msg, "\n")
+	joined := strings.Join(missing, ", ")
+
+	switch placement {
+	case TicketPlacementPrefix:
+		return joinMessage(joined+" "+header, rest)
+	case TicketPlacementScope:
+		// Only an empty scope can hold the ticket; otherwise fall back to a footer
+		if h := validator.ParseHeader(header); h.WellFormed && h.Scope == "" {
+			h.Scope = joined
+			return joinMessage(h.FormatHeader(validator.StyleConventional), rest)
+		}
+	}
+
+	if footer == "" {
+		footer = "Refs"
+	}
+	return strings.TrimRight(msg, "\n") + footerSeparator(msg) + footer + ": " + joined
+}
+
+// joinMessage reassembles a commit message from its header and remaining lines
+func joinMessage(header, rest string) string {
+	if rest == "" {
+		return header
+	}
+	return header + "\n" + rest
+}
+
+// footerSeparator returns the separator needed before appending a footer line
+func footerSeparator(msg string) string {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	last := lines[len(lines)-1]
+	if len(lines) > 1 && footerPattern.MatchString(last) {
+		return "\n"
+	}
+	return "\n\n"
+}
+
+// footerPattern matches git trailer style footer lines such as "Refs: PAY-1"
+var footerPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z-]*(: | #)`)
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+// ------------------- tickets -------------------
+
+func TestExtractTickets(t *testing.T) {
+	tickets, err := ExtractTickets("feature/PAY-1234-refund-flow", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(tickets, []string{"PAY-1234"}) {
+		t.Errorf("unexpected tickets: %v", tickets)
+	}
+
+	if _, err := ExtractTickets("main", "("); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestApplyTickets(t *testing.T) {
+	tests := []struct {
+		placement string
+		footer    string
+		msg       string
+		want      string
+	}{
+		{TicketPlacementPrefix, "", "feat: add refund flow", "PAY-1 feat: add refund flow"},
+		{TicketPlacementScope, "", "feat: add refund flow", "feat(PAY-1): add refund flow"},
+		{TicketPlacementScope, "", "feat(api): add refund flow", "feat(api): add refund flow\n\nRefs: PAY-1"},
+		{TicketPlacementFooter, "", "feat: add refund flow\n\nAdd endpoint.", "feat: add refund flow\n\nAdd endpoint.\n\nRefs: PAY-1"},
+		{TicketPlacementFooter, "", "feat: add refund flow (PAY-1)", "feat: add refund flow (PAY-1)"},
+		{TicketPlacementFooter, "Bug", "Add refund flow", "Add refund flow\n\nBug: PAY-1"},
+		{TicketPlacementScope, "", "fix: typo: readme", "fix(PAY-1): typo: readme"},
+		{TicketPlacementScope, "", "✨ feat: add refund flow", "✨ feat(PAY-1): add refund flow"},
+		{TicketPlacementScope, "", "Update README: fix link", "Update README: fix link\n\nRefs: PAY-1"},
+	}
+
+	for _, tt := range tests {
+		if got := ApplyTickets(tt.msg, []string{"PAY-1"}, tt.placement, tt.footer); got != tt.want {
+			t.Errorf("ApplyTickets(%q, %s) = %q, want %q", tt.msg, tt.placement, got, tt.want)
+		}
+	}
+}
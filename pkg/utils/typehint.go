@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/rezatg/gitc/pkg/validator"
+)
+
+// Type hint modes controlling how the commit type inferred from paths is used
+const (
+	TypeHintModeOff     = "off"     // do not infer a type
+	TypeHintModeHint    = "hint"    // pass the type to the prompt as a strong hint
+	TypeHintModeEnforce = "enforce" // require the type and enforce it in the output
+)
+
+// TypeRule maps files matching a glob pattern to a commit type
+type TypeRule struct {
+	Pattern string
+	Type    string
+}
+
+// DefaultTypeRules are applied after any configured rules
+var DefaultTypeRules = []TypeRule{
+	{Pattern: "*.md", Type: "docs"},
+	{Pattern: "*.rst", Type: "docs"},
+	{Pattern: "docs/**", Type: "docs"},
+	{Pattern: "*_test.go", Type: "test"},
+	{Pattern: ".github/workflows/**", Type: "ci"},
+	{Pattern: ".gitlab-ci.yml", Type: "ci"},
+	{Pattern: "Dockerfile", Type: "build"},
+	{Pattern: "*.Dockerfile", Type: "build"},
+	{Pattern: "Makefile", Type: "build"},
+}
+
+// ValidTypeHintMode reports whether mode is a supported type hint mode
+func ValidTypeHintMode(mode string) bool {
+	switch mode {
+	case TypeHintModeOff, TypeHintModeHint, TypeHintModeEnforce:
+		return true
+	}
+	return false
+}
+
+// InferType computes a likely commit type from the staged files. Every file is
+// mapped by the first matching rule, with rules taking precedence over
+// DefaultTypeRules. A type is returned only when every file maps to the same
+// type; mixed or unmatched changes return an empty string so the model decides.
+func InferType(files []string, rules []TypeRule) string {
+	rules = append(append([]TypeRule{}, rules...), DefaultTypeRules...)
+
+	inferred := ""
+	for _, file := range files {
+		fileType := ""
+		for _, rule := range rules {
+			if MatchGlob(rule.Pattern, file) {
+				fileType = rule.Type
+				break
+			}
+		}
+
+		if fileType == "" || (inferred != "" && fileType != inferred) {
+			return ""
+		}
+		inferred = fileType
+	}
+
+	return inferred
+}
+
+// EnforceType rewrites the type of a Conventional Commits header. Messages
+// without a recognizable header get the type prepended.
+func EnforceType(msg, commitType string) string {
+	header, rest, _ := strings.Cut(msg, "\n")
+	h := validator.ParseHeader(header)
+	if !h.WellFormed {
+		return joinMessage(commitType+": "+header, rest)
+	} else if strings.EqualFold(h.Type, commitType) {
+		return msg
+	}
+
+	h.Type = commitType
+	return joinMessage(h.FormatHeader(validator.StyleConventional), rest)
+}
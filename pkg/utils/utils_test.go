@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rezatg/gitc/pkg/config"
+	"github.com/rezatg/gitc/pkg/validator"
+)
+
+// ------------------- convention -------------------
+
+func TestApplyConventionAffixes(t *testing.T) {
+	conv := &config.Convention{Prefix: "[{ticket}]", Suffix: "(squad-a)"}
+
+	got := ApplyConventionAffixes("feat: add refund flow\n\nBody.", conv, []string{"PAY-1"})
+	if want := "[PAY-1] feat: add refund flow (squad-a)\n\nBody."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The ticket prefix is skipped when the branch has no ticket
+	if got := ApplyConventionAffixes("feat: add refund flow", conv, nil); got != "feat: add refund flow (squad-a)" {
+		t.Errorf("unexpected message without tickets: %q", got)
+	}
+
+	if got := ConventionAffixLength(conv, []string{"PAY-1"}); got != len("[PAY-1]  (squad-a)") {
+		t.Errorf("expected the affixes and their separators to be counted, got %d", got)
+	}
+
+	if re := AffixPattern(conv.Prefix, ""); re == nil || re.FindString("[PAY-1, PAY-2] feat: x") != "[PAY-1, PAY-2] " {
+		t.Errorf("expected prefix pattern to match rendered prefix, got %v", re)
+	}
+}
+
+// ------------------- presets -------------------
+
+func TestPresets_ExamplesValidate(t *testing.T) {
+	for _, preset := range Presets {
+		if len(preset.Examples) < 2 {
+			t.Errorf("preset %s needs at least two examples", preset.Name)
+		}
+
+		rules := validator.DefaultRules()
+		rules.Style = preset.Style
+		rules.Types = preset.ValidationTypes(nil)
+		rules.SubjectCase = preset.SubjectCase
+		rules.RequiredFooters = preset.Footers
+		for _, example := range preset.Examples {
+			if violations := validator.Validate(example, rules); len(violations) > 0 {
+				t.Errorf("preset %s example %q violates %v", preset.Name, example, violations)
+			}
+		}
+	}
+
+	if _, ok := LookupPreset(""); !ok {
+		t.Error("expected the default preset for an empty name")
+	}
+	if ValidPreset("svn") {
+		t.Error("expected unknown preset to be invalid")
+	}
+}
+
+func TestPreset_TicketPlacement(t *testing.T) {
+	tests := []struct {
+		preset string
+		msg    string
+		want   string
+	}{
+		{"conventional", "feat: add refund flow", "feat(PAY-1): add refund flow"},
+		{"kernel", "mm: remove helper", "mm: remove helper\n\nRefs: PAY-1"},
+		{"chromium", "Omnibox: Trim whitespace", "Omnibox: Trim whitespace\n\nBug: PAY-1"},
+		{"plain", "Fix: handle empty input", "Fix: handle empty input\n\nRefs: PAY-1"},
+	}
+
+	for _, tt := range tests {
+		preset, ok := LookupPreset(tt.preset)
+		if !ok {
+			t.Fatalf("unknown preset %s", tt.preset)
+		}
+		got := ApplyTickets(tt.msg, []string{"PAY-1"}, preset.TicketPlacement(TicketPlacementScope), preset.TicketFooter)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.preset, got, tt.want)
+		}
+	}
+}
+
+// ------------------- templates -------------------
+
+func TestLoadPromptTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, TemplateCommit), []byte("On {{.Branch}}: {{join .Files \", \"}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := LoadPromptTemplates(dir, filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	prompt, err := templates.Render(TemplateCommit, PromptData{Branch: "main", Files: []string{"a.go", "b.go"}})
+	if err != nil || prompt != "On main: a.go, b.go" {
+		t.Errorf("unexpected prompt %q, %v", prompt, err)
+	}
+	if templates.Source(TemplateCommit) != filepath.Join(dir, TemplateCommit) || templates.Source(TemplateSystem) != TemplateBuiltin {
+		t.Errorf("unexpected sources: %q, %q", templates.Source(TemplateCommit), templates.Source(TemplateSystem))
+	}
+
+	if _, err := templates.Render(TemplateSystem, PromptData{}); err != nil {
+		t.Errorf("expected built-in system template to render, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, TemplateSplit), []byte("{{.Diff"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPromptTemplates(dir); err == nil || !strings.Contains(err.Error(), TemplateSplit) {
+		t.Errorf("expected error naming the invalid template, got %v", err)
+	}
+}
+
+func TestBuiltinTemplates_Examples(t *testing.T) {
+	templates, err := LoadPromptTemplates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	examples := []string{"feat(api): add token refresh", "fix: close idle connections"}
+	for name := range defaultTemplates {
+		for n := range len(examples) + 1 {
+			prompt, err := templates.Render(name, PromptData{Format: "type: summary", Examples: examples[:n]})
+			if err != nil {
+				t.Errorf("%s with %d examples: %v", name, n, err)
+			} else if n > 0 && name != TemplateSystem && !strings.Contains(prompt, "feat(api): add token refresh") {
+				t.Errorf("%s with %d examples does not show them:\n%s", name, n, prompt)
+			}
+		}
+	}
+}
+
+// ------------------- examples -------------------
+
+func TestSelectExamples(t *testing.T) {
+	history := []string{
+		"wip",
+		"feat(api): add token refresh\n\nSigned-off-by: A <a@example.com>",
+		"Merge branch 'main' into feature",
+		"feat(api): add token refresh",
+		"fix: close idle connections",
+		"docs: describe cache settings",
+	}
+
+	examples := SelectExamples(history, validator.DefaultRules(), 2)
+	want := []string{"feat(api): add token refresh", "fix: close idle connections"}
+	if !slices.Equal(examples, want) {
+		t.Errorf("expected %q, got %q", want, examples)
+	}
+
+	if examples := SelectExamples(history[:3], validator.DefaultRules(), 3); examples != nil {
+		t.Errorf("expected no examples with a single valid commit, got %q", examples)
+	}
+
+	// A single example is enough when only one is asked for
+	if examples := SelectExamples(history[:3], validator.DefaultRules(), 1); !slices.Equal(examples, want[:1]) {
+		t.Errorf("expected a single example, got %q", examples)
+	}
+	if examples := SelectExamples([]string{"fix: typo: readme", "docs(readme): note: y"}, validator.DefaultRules(), 2); len(examples) != 2 {
+		t.Errorf("expected subjects with colons to be valid examples, got %q", examples)
+	}
+}
+
+// ------------------- type hints -------------------
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{[]string{"README.md", "docs/guide.md"}, "docs"},
+		{[]string{"internal/git/git_test.go"}, "test"},
+		{[]string{".github/workflows/ci.yml"}, "ci"},
+		{[]string{"Dockerfile", "Makefile"}, "build"},
+		{[]string{"README.md", "main.go"}, ""},
+		{[]string{"README.md", "git_test.go"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := InferType(tt.files, nil); got != tt.want {
+			t.Errorf("InferType(%v) = %q, want %q", tt.files, got, tt.want)
+		}
+	}
+
+	if got := InferType([]string{"CHANGELOG.md"}, []TypeRule{{Pattern: "CHANGELOG.md", Type: "chore"}}); got != "chore" {
+		t.Errorf("expected configured rule to take precedence, got %q", got)
+	}
+}
+
+func TestEnforceType(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"feat(api)!: drop v1\n\nBody.", "docs(api)!: drop v1\n\nBody."},
+		{"fix: typo: readme", "docs: typo: readme"},
+		{"✏️ fix: typo", "✏️ docs: typo"},
+		{"Update README: fix link", "docs: Update README: fix link"},
+	}
+
+	for _, tt := range tests {
+		if got := EnforceType(tt.msg, "docs"); got != tt.want {
+			t.Errorf("EnforceType(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
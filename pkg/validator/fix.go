@@ -0,0 +1,169 @@
+package validator
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// typeAliases maps frequent misspellings of commit types to the canonical type
+var typeAliases = map[string]string{
+	"feature": "feat", "features": "feat", "bugfix": "fix", "hotfix": "fix",
+	"doc": "docs", "documentation": "docs", "tests": "test", "testing": "test",
+	"refactoring": "refactor", "performance": "perf", "chores": "chore",
+}
+
+// fallbackEmoji is added to Gitmoji style headers without an emoji
+const fallbackEmoji = "🔧"
+
+// Fix rewrites a commit message locally so that it satisfies the rules as far
+// as possible without changing its meaning. It is the last resort when the
+// AI provider keeps producing invalid messages.
+func Fix(raw string, rules Rules) string {
+	style := rules.style()
+	msg := ParseStyle(raw, style)
+
+	switch style {
+	case StyleConventional:
+		types := rules.Types
+		if types == nil {
+			types = DefaultTypes
+		}
+		if !msg.WellFormed {
+			msg.Type = fallbackType(types)
+		}
+		msg.Type = fixType(msg.Type, types)
+	case StyleGitmoji:
+		if msg.Emoji == "" {
+			msg.Emoji = fallbackEmoji
+		}
+	}
+
+	if rules.Scope != "" {
+		msg.Scope = rules.Scope
+	} else if style == StyleConventional && msg.Scope != "" && len(rules.Scopes) > 0 && !slices.Contains(rules.Scopes, msg.Scope) {
+		msg.Scope = "" // an unknown scope is worse than none
+	}
+
+	msg.Subject = strings.TrimRight(strings.TrimSpace(msg.Subject), ". ")
+	if rules.Imperative {
+		if word, ok := nonImperative(msg.Subject); ok {
+			msg.Subject = Imperative(word) + strings.TrimPrefix(msg.Subject, word)
+		}
+	}
+
+	msg.Subject = withCase(msg.Subject, rules.SubjectCase)
+
+	msg.Header = msg.FormatHeader(style)
+	if rules.MaxHeaderLength > 0 {
+		if excess := utf8.RuneCountInString(msg.Header) - rules.MaxHeaderLength; excess > 0 {
+			msg.Subject = truncateWords(msg.Subject, utf8.RuneCountInString(msg.Subject)-excess)
+			msg.Header = msg.FormatHeader(style)
+		}
+	}
+
+	if rules.MaxBodyLineLength > 0 && msg.Body != "" {
+		msg.Body = wrapLines(msg.Body, rules.MaxBodyLineLength)
+	}
+
+	return msg.String()
+}
+
+// withCase changes the case of the first letter of the subject
+func withCase(subject, subjectCase string) string {
+	if subject == "" {
+		return subject
+	}
+
+	first, size := utf8.DecodeRuneInString(subject)
+	switch subjectCase {
+	case "lower":
+		return string(unicode.ToLower(first)) + subject[size:]
+	case "sentence":
+		return string(unicode.ToUpper(first)) + subject[size:]
+	}
+	return subject
+}
+
+// FormatHeader renders the header from its parts in the given style
+func (m Message) FormatHeader(style string) string {
+	switch style {
+	case StyleGitmoji:
+		return m.Emoji + " " + m.Subject
+	case StyleSubsystem:
+		if m.Scope == "" {
+			return m.Subject // the subsystem cannot be guessed
+		}
+		return m.Scope + ": " + m.Subject
+	case StylePlain:
+		return m.Subject
+	}
+
+	var builder strings.Builder
+	if m.Emoji != "" {
+		builder.WriteString(m.Emoji + " ")
+	}
+	builder.WriteString(m.Type)
+	if m.Scope != "" {
+		builder.WriteString("(" + m.Scope + ")")
+	}
+	if m.Breaking {
+		builder.WriteString("!")
+	}
+	builder.WriteString(": " + m.Subject)
+	return builder.String()
+}
+
+// fixType normalizes the case of a type and maps unknown types to allowed ones
+func fixType(commitType string, types []string) string {
+	commitType = strings.ToLower(commitType)
+	if len(types) == 0 || slices.Contains(types, commitType) {
+		return commitType
+	}
+	if alias, ok := typeAliases[commitType]; ok && slices.Contains(types, alias) {
+		return alias
+	}
+	return fallbackType(types)
+}
+
+// fallbackType picks the type used when the message has no usable type
+func fallbackType(types []string) string {
+	if len(types) == 0 || slices.Contains(types, "chore") {
+		return "chore"
+	}
+	return types[0]
+}
+
+// truncateWords shortens s to at most limit runes, cutting at a word boundary
+func truncateWords(s string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	runes := []rune(s)[:limit]
+	if i := strings.LastIndex(string(runes), " "); i > 0 {
+		return strings.TrimRight(string(runes)[:i], " ,;:-")
+	}
+	return string(runes)
+}
+
+// wrapLines re-wraps lines longer than width at word boundaries
+func wrapLines(text string, width int) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		for utf8.RuneCountInString(line) > width {
+			head := truncateWords(line, width)
+			if head == "" {
+				break
+			}
+			lines = append(lines, head)
+			line = strings.TrimSpace(strings.TrimPrefix(line, head))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,249 @@
+package validator
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Header styles understood by the parser and validator
+const (
+	StyleConventional = "conventional" // feat(api): add login page
+	StyleGitmoji      = "gitmoji"      // ✨ Add login page
+	StyleSubsystem    = "subsystem"    // net/ipv4: fix checksum offload
+	StylePlain        = "plain"        // Add login page
+)
+
+// headerForms describes the expected header of each style in violations
+var headerForms = map[string]string{
+	StyleConventional: "type(scope): subject",
+	StyleGitmoji:      "<gitmoji> subject",
+	StyleSubsystem:    "subsystem: subject",
+	StylePlain:        "subject",
+}
+
+// Message is a commit message split into its header parts, body and footers
+type Message struct {
+	Header   string
+	Emoji    string // leading Gitmoji, if any
+	Type     string
+	Scope    string // scope, or subsystem in the subsystem style
+	Breaking bool   // the header carries the "!" marker
+	Subject  string
+	Body     string
+	Footers  []Footer
+
+	// BlankAfterHeader reports whether the header is followed by a blank line
+	BlankAfterHeader bool
+	// WellFormed reports whether the header matched the expected style
+	WellFormed bool
+}
+
+// Footer is a git trailer style footer such as "Refs: PAY-1234" or "Fixes #42"
+type Footer struct {
+	Token     string
+	Separator string // ": " or " #"
+	Value     string
+}
+
+// String renders the footer line
+func (f Footer) String() string {
+	return f.Token + f.Separator + f.Value
+}
+
+var (
+	// headerPattern matches `type(scope)!: subject`
+	headerPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?: (.*)$`)
+	// footerPattern matches "Token: value", "Token #value" and "BREAKING CHANGE: value"
+	footerPattern = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[A-Za-z][A-Za-z-]*)(: | #)(.*)$`)
+	// gitmojiHeaderPattern matches `<gitmoji> subject`, the emoji possibly as :shortcode:
+	gitmojiHeaderPattern = regexp.MustCompile(`^(\S+)\s+(.*)$`)
+	// subsystemHeaderPattern matches `subsystem: subject` such as "mm/slab: fix leak"
+	subsystemHeaderPattern = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_./-]*): (.*)$`)
+	// shortcodePattern matches Gitmoji shortcodes such as :sparkles:
+	shortcodePattern = regexp.MustCompile(`^:[a-z0-9_+-]+:$`)
+)
+
+// scissorsLine marks the start of the diff appended by `git commit --verbose`
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// ignoredPrefixes mark messages generated by git itself, which are not linted
+var ignoredPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// Ignored reports whether a message was generated by git (merges, reverts,
+// fixups) and should be exempt from linting.
+func Ignored(raw string) bool {
+	header := Parse(raw).Header
+	for _, prefix := range ignoredPrefixes {
+		if strings.HasPrefix(header, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse splits a Conventional Commits message into header, body and footers.
+// Comment lines starting with '#' and everything below the scissors line are
+// ignored, as git does when committing.
+func Parse(raw string) Message {
+	return ParseStyle(raw, StyleConventional)
+}
+
+// ParseStyle is like Parse but splits the header according to the given style
+func ParseStyle(raw, style string) Message {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		if line == scissorsLine {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	var msg Message
+	if len(lines) == 0 {
+		return msg
+	}
+
+	msg.Header = lines[0]
+	msg.parseHeader(style)
+
+	rest := lines[1:]
+	msg.BlankAfterHeader = len(rest) == 0 || rest[0] == ""
+
+	// The last paragraph holds the footers if every line in it is a footer
+	start := len(rest)
+	for start > 0 && rest[start-1] != "" {
+		start--
+	}
+	if start < len(rest) && (start > 0 || !msg.BlankAfterHeader) && isFooterBlock(rest[start:]) {
+		for _, line := range rest[start:] {
+			m := footerPattern.FindStringSubmatch(line)
+			msg.Footers = append(msg.Footers, Footer{Token: m[1], Separator: m[2], Value: m[3]})
+		}
+		rest = rest[:start]
+	}
+
+	msg.Body = strings.Trim(strings.Join(rest, "\n"), "\n")
+	return msg
+}
+
+// ParseHeader splits a Conventional Commits header into its parts. WellFormed
+// reports whether the header matched; FormatHeader reassembles it.
+func ParseHeader(header string) Message {
+	msg := Message{Header: header}
+	msg.parseHeader(StyleConventional)
+	return msg
+}
+
+// parseHeader fills in the header parts used by the style
+func (m *Message) parseHeader(style string) {
+	switch style {
+	case StyleGitmoji:
+		parts := gitmojiHeaderPattern.FindStringSubmatch(m.Header)
+		if parts == nil || !isGitmoji(parts[1]) {
+			m.Subject = m.Header
+			return
+		}
+		m.WellFormed = true
+		m.Emoji = parts[1]
+		m.Subject = parts[2]
+		return
+	case StyleSubsystem:
+		parts := subsystemHeaderPattern.FindStringSubmatch(m.Header)
+		if parts == nil {
+			m.Subject = m.Header
+			return
+		}
+		m.WellFormed = true
+		m.Scope = parts[1]
+		m.Subject = parts[2]
+		return
+	case StylePlain:
+		m.WellFormed = true
+		m.Subject = m.Header
+		return
+	}
+
+	// A leading token is only taken for a Gitmoji when it cannot be the type
+	header, emoji := m.Header, ""
+	if parts := gitmojiHeaderPattern.FindStringSubmatch(header); parts != nil && isEmojiPrefix(parts[1]) {
+		header, emoji = parts[2], parts[1]
+	}
+
+	parts := headerPattern.FindStringSubmatch(header)
+	if parts == nil {
+		m.Subject = m.Header
+		return
+	}
+
+	m.WellFormed = true
+	m.Emoji = emoji
+	m.Type = parts[1]
+	m.Scope = parts[2]
+	m.Breaking = parts[3] == "!"
+	m.Subject = parts[4]
+}
+
+// String reassembles the message in canonical form
+func (m Message) String() string {
+	var builder strings.Builder
+
+	builder.WriteString(m.Header)
+	if m.Body != "" {
+		builder.WriteString("\n\n" + m.Body)
+	}
+	if len(m.Footers) > 0 {
+		builder.WriteString("\n")
+		for _, footer := range m.Footers {
+			builder.WriteString("\n" + footer.String())
+		}
+	}
+
+	return builder.String()
+}
+
+// isFooterBlock reports whether all lines are footers
+func isFooterBlock(lines []string) bool {
+	for _, line := range lines {
+		if !footerPattern.MatchString(line) {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+// isGitmoji reports whether token is an emoji or a Gitmoji shortcode
+func isGitmoji(token string) bool {
+	if shortcodePattern.MatchString(token) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(token)
+	return unicode.IsSymbol(r)
+}
+
+// isEmojiPrefix reports whether the first token of a Conventional Commits
+// header is a Gitmoji: a :shortcode:, or a token without ASCII letters that
+// does not end in ':' and so cannot be a type such as "fix:"
+func isEmojiPrefix(token string) bool {
+	if shortcodePattern.MatchString(token) {
+		return true
+	}
+	if strings.HasSuffix(token, ":") {
+		return false
+	}
+	for _, r := range token {
+		if r < utf8.RuneSelf && unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
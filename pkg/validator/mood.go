@@ -0,0 +1,106 @@
+package validator
+
+import "strings"
+
+// commonVerbs are imperative verbs frequently used in commit subjects. They are
+// used to recognize and undo inflected forms such as "adds" or "updated".
+var commonVerbs = toSet(
+	"add", "allow", "apply", "avoid", "bump", "build", "call", "change", "check",
+	"clean", "clear", "close", "configure", "convert", "copy", "create", "define",
+	"delete", "deprecate", "disable", "document", "drop", "enable", "ensure",
+	"expose", "extend", "extract", "fix", "format", "generate", "handle", "hide",
+	"ignore", "implement", "improve", "include", "increase", "inline", "install",
+	"introduce", "limit", "load", "log", "make", "merge", "migrate", "move",
+	"optimize", "parse", "pass", "prevent", "print", "read", "reduce", "refactor",
+	"release", "remove", "rename", "render", "reorder", "replace", "reset",
+	"resolve", "restore", "retry", "return", "revert", "rewrite", "run", "save",
+	"show", "simplify", "skip", "sort", "split", "stop", "store", "support",
+	"switch", "test", "track", "trim", "tweak", "unify", "update", "upgrade",
+	"use", "validate", "wrap", "write",
+)
+
+// irregularVerbs maps inflected forms that cannot be derived by stripping a suffix
+var irregularVerbs = map[string]string{
+	"made": "make", "wrote": "write", "written": "write", "ran": "run",
+	"built": "build", "did": "do", "does": "do", "has": "have", "had": "have",
+}
+
+// nonImperative returns the first word of the subject if it is an inflected
+// form of a known verb, such as "added", "adds" or "adding".
+func nonImperative(subject string) (string, bool) {
+	word, _, _ := strings.Cut(strings.TrimSpace(subject), " ")
+	lower := strings.ToLower(word)
+
+	if commonVerbs[lower] {
+		return "", false
+	}
+	if _, ok := irregularVerbs[lower]; ok {
+		return word, true
+	}
+	if _, ok := verbBase(lower); ok {
+		return word, true
+	}
+	return "", false
+}
+
+// Imperative converts an inflected verb like "Added", "adds" or "fixing" into
+// the imperative mood, preserving the case of the first letter. Unknown words
+// are returned unchanged.
+func Imperative(word string) string {
+	lower := strings.ToLower(word)
+
+	base, ok := irregularVerbs[lower]
+	if !ok {
+		if base, ok = verbBase(lower); !ok {
+			return word
+		}
+	}
+
+	if word[0] >= 'A' && word[0] <= 'Z' {
+		return strings.ToUpper(base[:1]) + base[1:]
+	}
+	return base
+}
+
+// verbBase finds the known verb an inflected lower case word derives from
+func verbBase(word string) (string, bool) {
+	var stems []string
+	switch {
+	case strings.HasSuffix(word, "ies"), strings.HasSuffix(word, "ied"):
+		stems = []string{word[:len(word)-3] + "y"}
+	case strings.HasSuffix(word, "es"):
+		stems = []string{word[:len(word)-2], word[:len(word)-1]}
+	case strings.HasSuffix(word, "s"):
+		stems = []string{word[:len(word)-1]}
+	case strings.HasSuffix(word, "ed"):
+		stem := word[:len(word)-2]
+		stems = []string{stem, stem + "e", undouble(stem)}
+	case strings.HasSuffix(word, "ing"):
+		stem := word[:len(word)-3]
+		stems = []string{stem, stem + "e", undouble(stem)}
+	}
+
+	for _, stem := range stems {
+		if commonVerbs[stem] {
+			return stem, true
+		}
+	}
+	return "", false
+}
+
+// undouble removes a doubled final consonant, e.g. "stopp" -> "stop"
+func undouble(stem string) string {
+	if n := len(stem); n >= 2 && stem[n-1] == stem[n-2] {
+		return stem[:n-1]
+	}
+	return stem
+}
+
+// toSet builds a lookup set from a list of words
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,234 @@
+// Package validator parses commit messages and checks them against
+// Conventional Commits rules or one of the other supported header styles.
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultTypes are the commit types allowed when no list is configured
+var DefaultTypes = []string{
+	"feat", "fix", "docs", "style", "refactor", "perf", "test",
+	"chore", "build", "ci", "revert", "init", "security",
+}
+
+// Rule names reported in violations
+const (
+	RuleHeaderFormat      = "header-format"
+	RuleHeaderMaxLength   = "header-max-length"
+	RuleTypeEnum          = "type-enum"
+	RuleTypeCase          = "type-case"
+	RuleScopeRequired     = "scope-required"
+	RuleScopeEnum         = "scope-enum"
+	RuleScopeEmpty        = "scope-empty"
+	RuleSubjectCase       = "subject-case"
+	RuleFooterRequired    = "footer-required"
+	RuleSubjectEmpty      = "subject-empty"
+	RuleSubjectFullStop   = "subject-full-stop"
+	RuleSubjectImperative = "subject-imperative"
+	RuleBodyLeadingBlank  = "body-leading-blank"
+	RuleBodyMaxLineLength = "body-max-line-length"
+)
+
+// Validation modes controlling what happens to messages that break the rules
+const (
+	ModeRepair = "repair" // re-prompt with the violations, then fix locally
+	ModeFix    = "fix"    // fix locally without re-prompting
+	ModeOff    = "off"    // accept messages as generated
+)
+
+// ValidMode reports whether mode is a supported validation mode
+func ValidMode(mode string) bool {
+	switch mode {
+	case ModeRepair, ModeFix, ModeOff:
+		return true
+	}
+	return false
+}
+
+// Rules configures which checks are applied. Zero values disable the
+// corresponding length checks; a nil Types list means DefaultTypes.
+type Rules struct {
+	Style             string // header style, StyleConventional when empty
+	Types             []string
+	Scope             string   // required scope, if any
+	Scopes            []string // allowed scopes, any scope when empty
+	RequireScope      bool
+	SubjectCase       string // "lower", "sentence" or empty for any
+	MaxHeaderLength   int
+	MaxBodyLineLength int
+	RequiredFooters   []string
+	Imperative        bool
+
+	// HeaderPrefix and HeaderSuffix match text added around the header by the
+	// convention (e.g. ticket IDs); it is stripped before the header is checked
+	HeaderPrefix *regexp.Regexp
+	HeaderSuffix *regexp.Regexp
+}
+
+// DefaultRules returns the rules used for generated messages
+func DefaultRules() Rules {
+	return Rules{
+		Types:             DefaultTypes,
+		MaxHeaderLength:   72,
+		MaxBodyLineLength: 100,
+		Imperative:        true,
+	}
+}
+
+// Violation is a single rule a message does not satisfy
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String renders the violation for display and for re-prompting
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// Validate checks a commit message against the rules. The convention prefix
+// and suffix are stripped before the header parts are checked but count
+// towards the length of the header.
+func Validate(raw string, rules Rules) []Violation {
+	msg := ParseStyle(rules.stripAffixes(raw), rules.Style)
+	msg.Header = ParseStyle(raw, rules.Style).Header
+	return ValidateMessage(msg, rules)
+}
+
+// style returns the header style, defaulting to Conventional Commits
+func (r Rules) style() string {
+	if r.Style == "" {
+		return StyleConventional
+	}
+	return r.Style
+}
+
+// stripAffixes removes the convention prefix and suffix from the header
+func (r Rules) stripAffixes(raw string) string {
+	if r.HeaderPrefix == nil && r.HeaderSuffix == nil {
+		return raw
+	}
+
+	header, rest, found := strings.Cut(raw, "\n")
+	if r.HeaderPrefix != nil {
+		if loc := r.HeaderPrefix.FindStringIndex(header); loc != nil && loc[0] == 0 {
+			header = header[loc[1]:]
+		}
+	}
+	if r.HeaderSuffix != nil {
+		for _, loc := range r.HeaderSuffix.FindAllStringIndex(header, -1) {
+			if loc[1] == len(header) {
+				header = header[:loc[0]]
+			}
+		}
+	}
+
+	if !found {
+		return header
+	}
+	return header + "\n" + rest
+}
+
+// ValidateMessage checks a parsed commit message against the rules
+func ValidateMessage(msg Message, rules Rules) []Violation {
+	var violations []Violation
+	add := func(rule, format string, args ...any) {
+		violations = append(violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	style := rules.style()
+	if !msg.WellFormed {
+		add(RuleHeaderFormat, "header %q must have the form %q", msg.Header, headerForms[style])
+	} else if style == StyleConventional {
+		types := rules.Types
+		if types == nil {
+			types = DefaultTypes
+		}
+		if msg.Type != strings.ToLower(msg.Type) {
+			add(RuleTypeCase, "type %q must be lower case", msg.Type)
+		}
+		if len(types) > 0 && !slices.Contains(types, strings.ToLower(msg.Type)) {
+			add(RuleTypeEnum, "type %q is not one of: %s", msg.Type, strings.Join(types, ", "))
+		}
+	}
+
+	// The subsystem of the subsystem style is checked like a scope
+	if msg.WellFormed && (style == StyleConventional || style == StyleSubsystem) {
+		switch {
+		case rules.Scope != "" && msg.Scope != rules.Scope:
+			add(RuleScopeRequired, "scope must be %q", rules.Scope)
+		case msg.Scope == "" && rules.RequireScope:
+			add(RuleScopeEmpty, "scope must not be empty")
+		case msg.Scope != "" && len(rules.Scopes) > 0 && !slices.Contains(rules.Scopes, msg.Scope):
+			add(RuleScopeEnum, "scope %q is not one of: %s", msg.Scope, strings.Join(rules.Scopes, ", "))
+		}
+	}
+
+	if length := utf8.RuneCountInString(msg.Header); rules.MaxHeaderLength > 0 && length > rules.MaxHeaderLength {
+		add(RuleHeaderMaxLength, "header is %d characters, the maximum is %d", length, rules.MaxHeaderLength)
+	}
+
+	subject := strings.TrimSpace(msg.Subject)
+	switch {
+	case subject == "":
+		add(RuleSubjectEmpty, "subject must not be empty")
+	case strings.HasSuffix(subject, "."):
+		add(RuleSubjectFullStop, "subject must not end with a period")
+	}
+	if subject != "" && !subjectHasCase(subject, rules.SubjectCase) {
+		add(RuleSubjectCase, "subject must be in %s case", rules.SubjectCase)
+	}
+	if rules.Imperative && subject != "" {
+		if word, ok := nonImperative(subject); ok {
+			add(RuleSubjectImperative, "subject must use the imperative mood (%q instead of %q)", Imperative(word), word)
+		}
+	}
+
+	if !msg.BlankAfterHeader {
+		add(RuleBodyLeadingBlank, "header must be followed by a blank line")
+	}
+
+	if rules.MaxBodyLineLength > 0 {
+		for i, line := range strings.Split(msg.Body, "\n") {
+			if length := utf8.RuneCountInString(line); length > rules.MaxBodyLineLength {
+				add(RuleBodyMaxLineLength, "body line %d is %d characters, the maximum is %d", i+1, length, rules.MaxBodyLineLength)
+			}
+		}
+	}
+
+	for _, token := range rules.RequiredFooters {
+		if !hasFooter(msg.Footers, token) {
+			add(RuleFooterRequired, "footer %q is required", token)
+		}
+	}
+
+	return violations
+}
+
+// subjectHasCase reports whether the first letter of the subject matches the case
+func subjectHasCase(subject, subjectCase string) bool {
+	first, _ := utf8.DecodeRuneInString(subject)
+	switch subjectCase {
+	case "lower":
+		return !unicode.IsUpper(first)
+	case "sentence":
+		return !unicode.IsLower(first)
+	}
+	return true
+}
+
+// hasFooter reports whether a footer with the token is present
+func hasFooter(footers []Footer, token string) bool {
+	for _, footer := range footers {
+		if strings.EqualFold(footer.Token, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package validator
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ------------------- Parse -------------------
+
+func TestParse(t *testing.T) {
+	msg := Parse("feat(api)!: drop v1 endpoints\n\nRemove the deprecated routes.\n\nBREAKING CHANGE: v1 is gone\nRefs #42\n")
+
+	if !msg.WellFormed || msg.Type != "feat" || msg.Scope != "api" || !msg.Breaking {
+		t.Errorf("unexpected header parts: %+v", msg)
+	}
+	if msg.Subject != "drop v1 endpoints" || msg.Body != "Remove the deprecated routes." {
+		t.Errorf("unexpected subject or body: %q / %q", msg.Subject, msg.Body)
+	}
+	if len(msg.Footers) != 2 || msg.Footers[1].String() != "Refs #42" {
+		t.Errorf("unexpected footers: %+v", msg.Footers)
+	}
+}
+
+func TestParse_GitmojiAndComments(t *testing.T) {
+	msg := Parse("✨ feat: add login\n# Please enter the commit message\n")
+	if !msg.WellFormed || msg.Emoji != "✨" || msg.Type != "feat" || msg.Body != "" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	if msg := Parse("Merge branch main: sync"); msg.WellFormed {
+		t.Errorf("expected plain header not to be conventional: %+v", msg)
+	}
+	if msg := Parse(":sparkles: feat(ui): add menu"); msg.Emoji != ":sparkles:" || msg.Type != "feat" || msg.Scope != "ui" {
+		t.Errorf("unexpected shortcode message: %+v", msg)
+	}
+}
+
+func TestParse_ColonInSubject(t *testing.T) {
+	tests := []struct {
+		header  string
+		typ     string
+		scope   string
+		subject string
+	}{
+		{"fix: typo: readme", "fix", "", "typo: readme"},
+		{"docs(readme): note: y", "docs", "readme", "note: y"},
+		{"🐛 fix: handle key: value pairs", "fix", "", "handle key: value pairs"},
+	}
+
+	for _, tt := range tests {
+		msg := Parse(tt.header)
+		if !msg.WellFormed || msg.Type != tt.typ || msg.Scope != tt.scope || msg.Subject != tt.subject {
+			t.Errorf("Parse(%q) = %+v", tt.header, msg)
+		}
+	}
+}
+
+func TestParse_Scissors(t *testing.T) {
+	raw := "feat: add login\n\nAdd the form.\n# Please enter the commit message\n" +
+		"# ------------------------ >8 ------------------------\n" +
+		"diff --git a/login.go b/login.go\n+Refs: PAY-1\n"
+
+	msg := Parse(raw)
+	if msg.Body != "Add the form." || len(msg.Footers) != 0 {
+		t.Errorf("expected the diff below the scissors line to be dropped: %+v", msg)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"Merge branch 'main' into feature":                   true,
+		"Merge pull request #42 from org/feature":            true,
+		"Revert \"feat: add login\"\n\nThis reverts a1b2c3.": true,
+		"fixup! feat: add login":                             true,
+		"squash! feat: add login":                            true,
+		"# comment\nfixup! feat: add login":                  true,
+		"feat: merge duplicate settings":                     false,
+		"Reverted the login page":                            false,
+	} {
+		if got := Ignored(msg); got != want {
+			t.Errorf("Ignored(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+// ------------------- Validate -------------------
+
+func rulesOf(violations []Violation) []string {
+	rules := make([]string, len(violations))
+	for i, v := range violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want []string
+	}{
+		{"feat: add JWT middleware\n\nAdd access token check.", nil},
+		{"Added JWT middleware", []string{RuleHeaderFormat, RuleSubjectImperative}},
+		{"feature: add JWT middleware", []string{RuleTypeEnum}},
+		{"feat: adds JWT middleware.", []string{RuleSubjectFullStop, RuleSubjectImperative}},
+		{"feat: add JWT middleware\nAdd access token check.", []string{RuleBodyLeadingBlank}},
+		{"feat: " + strings.Repeat("x", 80), []string{RuleHeaderMaxLength}},
+		{"fix: typo: readme", nil},
+		{"docs(readme): note: y", nil},
+	}
+
+	for _, tt := range tests {
+		if got := rulesOf(Validate(tt.msg, DefaultRules())); !slices.Equal(got, tt.want) {
+			t.Errorf("Validate(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_Styles(t *testing.T) {
+	tests := []struct {
+		style string
+		msg   string
+		want  []string
+	}{
+		{StyleGitmoji, "✨ Add login page", nil},
+		{StyleGitmoji, ":bug: Fix login redirect", nil},
+		{StyleGitmoji, "feat: add login page", []string{RuleHeaderFormat}},
+		{StyleSubsystem, "net/ipv4: fix checksum offload", nil},
+		{StyleSubsystem, "Fix checksum offload", []string{RuleHeaderFormat}},
+		{StylePlain, "Add login page", nil},
+		{StylePlain, "Added login page.", []string{RuleSubjectFullStop, RuleSubjectImperative}},
+	}
+
+	for _, tt := range tests {
+		rules := DefaultRules()
+		rules.Style = tt.style
+		if got := rulesOf(Validate(tt.msg, rules)); !slices.Equal(got, tt.want) {
+			t.Errorf("Validate(%q, %s) = %v, want %v", tt.msg, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_AffixesCountTowardsLength(t *testing.T) {
+	rules := DefaultRules()
+	rules.MaxHeaderLength = 30
+	rules.HeaderPrefix = regexp.MustCompile(`\[[A-Z]+-[0-9]+\] `)
+
+	if got := rulesOf(Validate("[PAY-1] feat: add refund flow", rules)); len(got) != 0 {
+		t.Errorf("expected the prefix to be stripped before the header is parsed, got %v", got)
+	}
+	if got := rulesOf(Validate("[PAY-1234] feat: add the refund flow", rules)); !slices.Equal(got, []string{RuleHeaderMaxLength}) {
+		t.Errorf("expected the prefix to count towards the header length, got %v", got)
+	}
+}
+
+// ------------------- Fix -------------------
+
+func TestFix(t *testing.T) {
+	rules := DefaultRules()
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"Feature: Updated the parser.", "feat: Update the parser"},
+		{"fixing crash on nil config", "chore: fix crash on nil config"},
+		{"fix: stop panic\nAdd nil check.", "fix: stop panic\n\nAdd nil check."},
+		{"fix: typo: readme", "fix: typo: readme"},
+		{"docs(readme): note: y", "docs(readme): note: y"},
+	}
+
+	for _, tt := range tests {
+		got := Fix(tt.msg, rules)
+		if got != tt.want {
+			t.Errorf("Fix(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+		if violations := Validate(got, rules); len(violations) > 0 {
+			t.Errorf("Fix(%q) still violates %v", tt.msg, violations)
+		}
+	}
+
+	long := Fix("feat: "+strings.Repeat("word ", 30), rules)
+	if len(long) > rules.MaxHeaderLength {
+		t.Errorf("expected header to be truncated, got %d characters", len(long))
+	}
+}
+
+func TestFix_Styles(t *testing.T) {
+	tests := []struct {
+		style string
+		msg   string
+		want  string
+	}{
+		{StyleGitmoji, "Added login page.", "🔧 Add login page"},
+		{StyleSubsystem, "mm: Removed unused helper", "mm: remove unused helper"},
+		{StylePlain, "fixed crash on nil config", "Fix crash on nil config"},
+	}
+
+	for _, tt := range tests {
+		rules := DefaultRules()
+		rules.Style = tt.style
+		rules.SubjectCase = "sentence"
+		if tt.style == StyleSubsystem {
+			rules.SubjectCase = "lower"
+		}
+		if got := Fix(tt.msg, rules); got != tt.want {
+			t.Errorf("Fix(%q, %s) = %q, want %q", tt.msg, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestImperative(t *testing.T) {
+	for word, want := range map[string]string{
+		"Added": "Add", "updates": "update", "fixing": "fix", "stopped": "stop",
+		"applies": "apply", "Removed": "Remove", "made": "make", "unknown": "unknown",
+	} {
+		if got := Imperative(word); got != want {
+			t.Errorf("Imperative(%q) = %q, want %q", word, got, want)
+		}
+	}
+}